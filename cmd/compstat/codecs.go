@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aomarai/compstat/internal/codec"
+)
+
+// codecInfo describes one registered codec for the codecs subcommand
+type codecInfo struct {
+	Name          string          `json:"name"`
+	Binary        string          `json:"binary,omitempty"`
+	Path          string          `json:"path,omitempty"`
+	Available     bool            `json:"available"`
+	Version       string          `json:"version,omitempty"`
+	DefaultLevels string          `json:"default_levels"`
+	Threading     bool            `json:"threading"`
+	IOMode        string          `json:"io_mode"`
+	Streaming     bool            `json:"streaming"`
+	Params        []string        `json:"params,omitempty"`
+	Flags         map[string]bool `json:"flags,omitempty"`
+}
+
+// runCodecs implements the codecs subcommand
+func runCodecs(args []string) {
+	fs := flag.NewFlagSet("codecs", flag.ExitOnError)
+	codecs := fs.String("codecs", "", "Comma-separated codecs to show (default: all registered); exits 1 if any is unavailable")
+	jsonOutput := fs.Bool("json", false, "Print JSON instead of a table")
+	codecConfig := fs.String("codec-config", "", "File (.yaml, .toml or .json) defining additional external codecs")
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	// A broken default codec file should not hide the built-in codecs. The
+	// warning goes to stderr to keep -json output parseable.
+	if err := loadDefaultCodecs(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	if *codecConfig != "" {
+		if err := loadCodecConfig(*codecConfig); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	names := make([]string, 0)
+	if *codecs == "" {
+		for name := range codec.Registry {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	} else {
+		for _, name := range strings.Split(*codecs, ",") {
+			name = strings.TrimSpace(name)
+			if _, ok := codec.Registry[name]; !ok {
+				fmt.Printf("Error: unknown codec %q\n", name)
+				os.Exit(1)
+			}
+			names = append(names, name)
+		}
+	}
+
+	infos := make([]codecInfo, 0, len(names))
+	missing := 0
+	for _, name := range names {
+		info := describeCodec(codec.Registry[name])
+		if !info.Available {
+			missing++
+		}
+		infos = append(infos, info)
+	}
+
+	if *jsonOutput {
+		data, err := json.MarshalIndent(infos, "", "  ")
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+	} else {
+		printCodecs(infos)
+	}
+
+	// Listing specific codecs doubles as an installation check
+	if *codecs != "" && missing > 0 {
+		os.Exit(1)
+	}
+}
+
+// describeCodec probes c and collects its properties
+func describeCodec(c codec.Codec) codecInfo {
+	info := codecInfo{
+		Name:          c.Name(),
+		Binary:        c.Binary(),
+		Available:     c.IsAvailable(),
+		DefaultLevels: codec.FormatLevelRange(c.Levels()),
+		Threading:     c.SupportsThreading(),
+		IOMode:        c.IOMode().String(),
+	}
+	switch c.(type) {
+	case codec.StreamingCodec, codec.InProcessCodec:
+		info.Streaming = true
+	}
+	if t, ok := c.(codec.Tunable); ok {
+		for _, p := range t.SupportedParams() {
+			info.Params = append(info.Params, p.Name)
+		}
+	}
+	if info.Available {
+		caps := codec.Probe(c)
+		info.Path = caps.Path
+		info.Version = caps.Version
+		info.Flags = caps.Flags
+	}
+	return info
+}
+
+// printCodecs prints infos as a table followed by any unsupported flags
+func printCodecs(infos []codecInfo) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "CODEC\tBINARY\tPATH\tVERSION\tDEFAULT LEVELS\tTHREADS\tIO MODE\tPARAMS")
+	for _, info := range infos {
+		binary, path, version := info.Binary, info.Path, info.Version
+		if binary == "" {
+			binary = "-"
+			path = "(in-process)"
+		} else if !info.Available {
+			path = "not found"
+		}
+		if version == "" {
+			version = "-"
+		}
+		threads := "no"
+		if info.Threading {
+			threads = "yes"
+		}
+		params := "-"
+		if len(info.Params) > 0 {
+			params = strings.Join(info.Params, ",")
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			info.Name, binary, path, version, info.DefaultLevels, threads, info.IOMode, params)
+	}
+	_ = tw.Flush()
+
+	for _, info := range infos {
+		unsupported := make([]string, 0)
+		for name, ok := range info.Flags {
+			if !ok {
+				unsupported = append(unsupported, name)
+			}
+		}
+		if len(unsupported) > 0 {
+			sort.Strings(unsupported)
+			fmt.Printf("Note: %s %s does not support %s\n", info.Name, info.Version, strings.Join(unsupported, ", "))
+		}
+	}
+}
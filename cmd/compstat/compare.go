@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aomarai/compstat/internal/analysis"
+	"github.com/aomarai/compstat/internal/benchmark"
+	"github.com/aomarai/compstat/internal/corpus"
+)
+
+// runCompare implements the compare subcommand. It exits with status 1 when
+// a metric regressed or when no configuration could be compared, so it can
+// gate upgrades in CI.
+func runCompare(args []string) {
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	baseline := fs.String("baseline", "", "Baseline results file (.csv or .json) (required)")
+	candidate := fs.String("candidate", "", "Candidate results file (.csv or .json) (required)")
+	threshold := fs.Float64("threshold", 5, "Regression threshold in percent of the baseline mean")
+	alpha := fs.Float64("alpha", 0.05, "Significance level of the Welch t-test across iterations")
+	metrics := fs.String("metrics", strings.Join(analysis.DefaultMetrics, ","), "Metrics to compare (ratio, compress, decompress)")
+	regressionsOnly := fs.Bool("regressions-only", false, "Only show configurations with a regression")
+	jsonOutput := fs.Bool("json", false, "Print the comparison as JSON")
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+	if *baseline == "" && fs.NArg() > 0 {
+		*baseline = fs.Arg(0)
+	}
+	if *candidate == "" && fs.NArg() > 1 {
+		*candidate = fs.Arg(1)
+	}
+	if *baseline == "" || *candidate == "" {
+		fmt.Println("Error: -baseline and -candidate are required")
+		fs.Usage()
+		os.Exit(1)
+	}
+	if *threshold < 0 || *alpha <= 0 || *alpha >= 1 {
+		fmt.Println("Error: -threshold must not be negative and -alpha must be between 0 and 1")
+		os.Exit(1)
+	}
+
+	metricList, err := analysis.ParseMetrics(*metrics)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	base, err := benchmark.LoadResults(*baseline)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	cand, err := benchmark.LoadResults(*candidate)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	report := analysis.Compare(base, cand, analysis.CompareOptions{
+		Metrics:   metricList,
+		Threshold: *threshold / 100,
+		Alpha:     *alpha,
+	})
+
+	if *jsonOutput {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+	} else {
+		printComparisons(report, metricList, *regressionsOnly)
+		fmt.Printf("\n%d configuration(s) compared on %s", len(report.Comparisons), report.MatchedOn)
+		if report.BaselineOnly > 0 || report.CandidateOnly > 0 {
+			fmt.Printf(", %d only in baseline, %d only in candidate", report.BaselineOnly, report.CandidateOnly)
+		}
+		fmt.Println()
+		fmt.Printf("%d regression(s) worse than %g%% at alpha %g\n", report.Regressions(), *threshold, *alpha)
+	}
+
+	if len(report.Comparisons) == 0 {
+		// Keep stdout valid JSON
+		_, _ = fmt.Fprintf(os.Stderr, "Error: no configurations in common (matched on %s: %d only in baseline, %d only in candidate)\n",
+			report.MatchedOn, report.BaselineOnly, report.CandidateOnly)
+		os.Exit(1)
+	}
+	if report.Regressions() > 0 {
+		os.Exit(1)
+	}
+}
+
+// metricHeaders names the table column of each metric
+var metricHeaders = map[string]string{
+	analysis.MetricRatio:      "RATIO",
+	analysis.MetricCompress:   "COMP MB/s",
+	analysis.MetricDecompress: "DECOMP MB/s",
+}
+
+func printComparisons(report analysis.CompareReport, metrics []string, regressionsOnly bool) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header := "FILE\tALGORITHM\tLEVEL\tPARAMS\tTHREADS\tVERSION"
+	for _, m := range metrics {
+		header += "\t" + metricHeaders[m]
+	}
+	_, _ = fmt.Fprintln(tw, header)
+	for _, c := range report.Comparisons {
+		if regressionsOnly && !c.Regressed() {
+			continue
+		}
+		version := c.CandidateVersion
+		if c.BaselineVersion != c.CandidateVersion {
+			version = fmt.Sprintf("%s -> %s", orUnknown(c.BaselineVersion), orUnknown(c.CandidateVersion))
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%d/%d\t%s",
+			corpus.DisplayName(c.FilePath), c.Algorithm, c.Level,
+			benchmark.ParamsLabel(c.Params, c.DictionaryBytes, c.RecordFormat),
+			c.CompressThreads, c.DecompressThreads, version)
+		for _, d := range c.Deltas {
+			_, _ = fmt.Fprintf(tw, "\t%s", formatDelta(d))
+		}
+		_, _ = fmt.Fprintln(tw)
+	}
+	_ = tw.Flush()
+	fmt.Println("\n* significant change, ! regression")
+}
+
+// formatDelta shows the candidate mean and its change from the baseline
+func formatDelta(d analysis.Delta) string {
+	value := fmt.Sprintf("%.2f", d.Candidate.Mean)
+	if d.Metric == analysis.MetricRatio {
+		value = fmt.Sprintf("%.4f", d.Candidate.Mean)
+	}
+	mark := ""
+	if d.Significant {
+		mark = "*"
+	}
+	if d.Regression {
+		mark += "!"
+	}
+	return fmt.Sprintf("%s (%+.1f%%)%s", value, d.Change*100, mark)
+}
+
+func orUnknown(version string) string {
+	if version == "" {
+		return "?"
+	}
+	return version
+}
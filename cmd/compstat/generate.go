@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aomarai/compstat/internal/corpus"
+)
+
+// runGenerate implements the generate subcommand, which writes generated
+// inputs to files so they can be inspected or reused with -files
+func runGenerate(args []string) {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	outDir := fs.String("out", ".", "Directory to write the generated files to")
+	fs.Usage = func() {
+		fmt.Println("Usage: compstat generate [-out dir] spec[,spec...]")
+		fmt.Println()
+		fmt.Println("Specs are kind:size[@seed] or tar:dir; kinds: " + strings.Join(corpus.Kinds(), ", "))
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	specs, err := corpus.ParseSpecs(strings.Join(fs.Args(), ","))
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.MkdirAll(*outDir, 0755); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, spec := range specs {
+		path := filepath.Join(*outDir, spec.FileName())
+		if err := corpus.WriteFile(path, spec); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s -> %s\n", spec, path)
+	}
+}
@@ -1,14 +1,10 @@
 package main
 
 import (
-	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
-	"runtime"
-	"strings"
 
-	"github.com/aomarai/compstat/internal/benchmark"
 	"github.com/aomarai/compstat/internal/codec"
 )
 
@@ -18,94 +14,96 @@ var (
 )
 
 func main() {
-	files := flag.String("files", "", "Comma-separated list of input files (required)")
-	codecs := flag.String("codecs", "", "Comma-separated codecs (default: all available)")
-	compThreads := flag.Int("compress-threads", 0, "Compression threads (default: CPU count)")
-	decompThreads := flag.Int("decompress-threads", 0, "Decompression threads (default: CPU count)")
-	iterations := flag.Int("iterations", 1, "Number of iterations per configuration")
-	tmpDir := flag.String("tmpdir", "", "Temporary directory (default: system temp)")
-	output := flag.String("output", "compstat_results.csv", "CSV output file")
-	jsonOutput := flag.String("json", "", "Optional JSON output file")
-	noVerify := flag.Bool("no-verify", false, "Skip decompression verification")
-	skipDecomp := flag.Bool("skip-decompression", false, "Skip decompression entirely")
-	parallelism := flag.Int("parallelism", 1, "Number of parallel benchmark jobs")
-	version := flag.Bool("version", false, "Show version information")
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "run":
+			runBenchmark(os.Args[2:])
+			return
+		case "recommend":
+			runRecommend(os.Args[2:])
+			return
+		case "compare":
+			runCompare(os.Args[2:])
+			return
+		case "report":
+			runReport(os.Args[2:])
+			return
+		case "selftest":
+			runSelfTest(os.Args[2:])
+			return
+		case "generate":
+			runGenerate(os.Args[2:])
+			return
+		case "codecs":
+			runCodecs(os.Args[2:])
+			return
+		case "help", "-h", "-help", "--help":
+			printUsage()
+			return
+		}
+	}
 
-	flag.Parse()
+	// Without a subcommand, arguments are run flags for backwards compatibility
+	runBenchmark(os.Args[1:])
+}
 
-	if *version {
-		fmt.Printf("compstat version %s (built %s)\n", Version, BuildTime)
-		os.Exit(0)
-	}
+func printUsage() {
+	fmt.Println(`Usage: compstat <command> [flags]
 
-	if *files == "" {
-		fmt.Println("Error: -files is required")
-		flag.Usage()
-		os.Exit(1)
-	}
+Commands:
+  run         Run benchmarks (default when no command is given)
+  recommend   Rank configurations from a results file against constraints
+  compare     Compare two results files and fail on regressions
+  report      Write results as a self-contained HTML report with charts
+  generate    Write generated benchmark inputs to files
+  codecs      List registered codecs, their availability and capabilities
+  selftest    Check that each codec produces output in its declared I/O mode
 
-	// Parse inputs
-	fileList := strings.Split(*files, ",")
-	for i := range fileList {
-		fileList[i] = strings.TrimSpace(fileList[i])
-	}
+Run "compstat <command> -h" for command flags.
 
-	codecList := make([]string, 0)
-	if *codecs == "" {
-		for name := range codec.Registry {
-			codecList = append(codecList, name)
-		}
-	} else {
-		for _, c := range strings.Split(*codecs, ",") {
-			codecList = append(codecList, strings.TrimSpace(c))
-		}
-	}
+run, selftest and codecs load custom codecs from $COMPSTAT_CODECS, or else
+from compstat/codecs.yaml (or .toml/.json) in the user config directory.`)
+}
 
-	cpuCount := runtime.NumCPU()
-	if *compThreads == 0 {
-		*compThreads = cpuCount
+// loadedCodecConfigs holds the absolute paths of the codec files loaded so
+// far, so a file named by both $COMPSTAT_CODECS and -codec-config is only
+// registered once
+var loadedCodecConfigs = make(map[string]bool)
+
+// loadDefaultCodecs registers custom codecs from the file named by
+// $COMPSTAT_CODECS or, failing that, from the user config directory. Only
+// the subcommands that run codecs load them, so a broken file does not get
+// in the way of reading results.
+func loadDefaultCodecs() error {
+	if path := os.Getenv("COMPSTAT_CODECS"); path != "" {
+		return loadCodecConfig(path)
 	}
-	if *decompThreads == 0 {
-		*decompThreads = cpuCount
-	}
-
-	tmpDirPath := *tmpDir
-	if tmpDirPath == "" {
-		tmpDirPath = filepath.Join(os.TempDir(), "compstat_tmp")
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil
 	}
-
-	config := benchmark.Config{
-		Files:               fileList,
-		Codecs:              codecList,
-		CompressThreads:     *compThreads,
-		DecompressThreads:   *decompThreads,
-		Iterations:          *iterations,
-		TmpDir:              tmpDirPath,
-		OutputCSV:           *output,
-		OutputJSON:          *jsonOutput,
-		VerifyDecompression: !*noVerify,
-		SkipDecompression:   *skipDecomp,
-		Parallelism:         *parallelism,
+	for _, name := range []string{"codecs.yaml", "codecs.yml", "codecs.toml", "codecs.json"} {
+		path := filepath.Join(dir, "compstat", name)
+		if _, err := os.Stat(path); err == nil {
+			return loadCodecConfig(path)
+		}
 	}
+	return nil
+}
 
-	runner, err := benchmark.NewRunner(config)
+// loadCodecConfig registers the custom codecs defined in path, unless that
+// file was loaded already
+func loadCodecConfig(path string) error {
+	abs, err := filepath.Abs(path)
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		abs = filepath.Clean(path)
 	}
-	defer runner.Close()
-
-	if err := runner.Run(); err != nil {
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+	if loadedCodecConfigs[abs] {
+		return nil
 	}
-
-	if config.OutputJSON != "" {
-		if err := runner.WriteJSONSummary(); err != nil {
-			fmt.Printf("Warning: Failed to write JSON: %v\n", err)
-		}
+	if _, err := codec.LoadExternalCodecs(path); err != nil {
+		return err
 	}
-
-	fmt.Printf("\n✓ Benchmark complete! Results: %s\n", config.OutputCSV)
-	fmt.Printf("  Total runs: %d\n", runner.ResultCount())
+	loadedCodecConfigs[abs] = true
+	return nil
 }
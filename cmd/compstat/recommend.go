@@ -0,0 +1,104 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/aomarai/compstat/internal/analysis"
+	"github.com/aomarai/compstat/internal/benchmark"
+	"github.com/aomarai/compstat/internal/corpus"
+)
+
+// runRecommend implements the recommend subcommand
+func runRecommend(args []string) {
+	fs := flag.NewFlagSet("recommend", flag.ExitOnError)
+	results := fs.String("results", "", "Results file written by compstat run (.csv or .json) (required)")
+	minComp := fs.Float64("min-compress", 0, "Minimum mean compression speed in MB/s")
+	minDecomp := fs.Float64("min-decompress", 0, "Minimum mean decompression speed in MB/s")
+	maxRatio := fs.Float64("max-ratio", 0, "Maximum mean compression ratio (compressed/uncompressed)")
+	weights := fs.String("weights", "ratio=1,compress=1,decompress=1", "Objective weights for ranking")
+	top := fs.Int("top", 10, "Number of configurations to show per file (0 for all)")
+	paretoOnly := fs.Bool("pareto-only", false, "Only show configurations on the Pareto frontier")
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+	if *results == "" && fs.NArg() > 0 {
+		*results = fs.Arg(0)
+	}
+	if *results == "" {
+		fmt.Println("Error: -results is required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	w, err := analysis.ParseWeights(*weights)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	loaded, err := benchmark.LoadResults(*results)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	constraints := analysis.Constraints{
+		MinCompressMBs:   *minComp,
+		MinDecompressMBs: *minDecomp,
+		MaxRatio:         *maxRatio,
+	}
+
+	// Ratios depend on the data, so rank each input file separately
+	byFile := make(map[string][]benchmark.Summary)
+	for _, s := range benchmark.Aggregate(loaded) {
+		byFile[s.FilePath] = append(byFile[s.FilePath], s)
+	}
+	files := make([]string, 0, len(byFile))
+	for f := range byFile {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		candidates := analysis.Recommend(byFile[file], constraints, w)
+		fmt.Printf("\n=== %s: %d of %d configuration(s) satisfy constraints ===\n",
+			corpus.DisplayName(file), len(candidates), len(byFile[file]))
+
+		if *paretoOnly {
+			filtered := candidates[:0]
+			for _, c := range candidates {
+				if c.Pareto {
+					filtered = append(filtered, c)
+				}
+			}
+			candidates = filtered
+		}
+		if *top > 0 && len(candidates) > *top {
+			candidates = candidates[:*top]
+		}
+		printCandidates(candidates)
+	}
+}
+
+func printCandidates(candidates []analysis.Candidate) {
+	if len(candidates) == 0 {
+		return
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "RANK\tALGORITHM\tLEVEL\tPARAMS\tTHREADS\tRATIO\tCOMP MB/s\tDECOMP MB/s\tPARETO\tSCORE")
+	for i, c := range candidates {
+		pareto := ""
+		if c.Pareto {
+			pareto = "*"
+		}
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t%d\t%s\t%d/%d\t%.4f\t%.2f\t%.2f\t%s\t%.3f\n",
+			i+1, c.Algorithm, c.Level, benchmark.ParamsLabel(c.Params, c.DictionaryBytes, c.RecordFormat), c.CompressThreads, c.DecompressThreads,
+			c.CompressionRatio.Mean, c.CompressionSpeedMBs.Mean, c.DecompressionSpeedMBs.Mean, pareto, c.Score)
+	}
+	_ = tw.Flush()
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aomarai/compstat/internal/benchmark"
+	"github.com/aomarai/compstat/internal/report"
+)
+
+// runReport implements the report subcommand
+func runReport(args []string) {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	output := fs.String("output", "compstat_report.html", "HTML file to write")
+	title := fs.String("title", "", "Report title (default \"compstat report\")")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: compstat report [-output file.html] results [results...]")
+		fmt.Println()
+		fmt.Println("Results are files written by compstat run (.csv or .json)")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	results := make([]benchmark.Result, 0)
+	for _, path := range fs.Args() {
+		loaded, err := benchmark.LoadResults(path)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		results = append(results, loaded...)
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	err = report.Write(f, results, report.Options{
+		Title:     *title,
+		Sources:   fs.Args(),
+		Generated: time.Now(),
+	})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Printf("Error: failed to write %s: %v\n", *output, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Report written to %s (%d runs)\n", *output, len(results))
+}
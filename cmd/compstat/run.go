@@ -0,0 +1,326 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/aomarai/compstat/internal/benchmark"
+	"github.com/aomarai/compstat/internal/codec"
+	"github.com/aomarai/compstat/internal/corpus"
+	"github.com/aomarai/compstat/internal/util"
+)
+
+// runBenchmark implements the run subcommand
+func runBenchmark(args []string) {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	codecConfig := fs.String("codec-config", "", "File (.yaml, .toml or .json) defining additional external codecs")
+	planPath := fs.String("plan", "", "Plan file (.yaml, .toml or .json) declaring the whole run; other flags override it")
+	files := fs.String("files", "", "Comma-separated input files, directories, globs (data/**/*.log) or @listfiles")
+	generate := fs.String("generate", "", "Generated inputs, e.g. json:1GiB,random:256MiB@7,tar:src (kinds: "+strings.Join(corpus.Kinds(), ", ")+")")
+	inputMode := fs.String("input-mode", benchmark.InputModeEach, "Benchmark inputs individually (each) or as one tar stream (concat)")
+	records := fs.String("records", "", "Split inputs into records compressed independently: lines, fixed:SIZE or length-prefixed (in-process codecs only)")
+	chunkSizes := fs.String("chunk-size", "", "Chunk sizes to sweep, each chunk compressed independently, e.g. 64KiB,1MiB; whole files always run as the baseline")
+	codecs := fs.String("codecs", "", "Comma-separated codecs (default: all available)")
+	levels := fs.String("levels", "", "Per-codec levels, e.g. zstd:-5..22,xz:0..9e,lz4:1,9,12 (default: each codec's standard sweep)")
+	var paramSpecs paramFlag
+	fs.Var(&paramSpecs, "param", "Codec parameter values to sweep, e.g. zstd:long=-,27,31 (repeatable; - leaves it unset)")
+	dictSamples := fs.String("dict-samples", "", "Comma-separated samples to train dictionaries from, same syntax as -files")
+	dictSizes := fs.String("dict-size", "", "Dictionary sizes to sweep, e.g. 0,16KiB,110KiB; 0 runs without a dictionary (default: 110KiB)")
+	compThreads := fs.String("compress-threads", "", "Compression thread counts, e.g. 4, 1,2,4 or 1..32:x2 (default: CPU count)")
+	decompThreads := fs.String("decompress-threads", "", "Decompression thread counts, same syntax (default: CPU count)")
+	iterations := fs.Int("iterations", 1, "Number of iterations per configuration")
+	tmpDir := fs.String("tmpdir", "", "Temporary directory (default: system temp)")
+	output := fs.String("output", "compstat_results.csv", "CSV output file")
+	jsonOutput := fs.String("json", "", "Optional JSON output file")
+	summaryOutput := fs.String("summary", "", "Optional CSV file for per-configuration statistics")
+	summaryJSONOutput := fs.String("summary-json", "", "Optional JSON file for per-configuration statistics")
+	corpusOutput := fs.String("corpus-summary", "", "Optional CSV or .json file for totals across all input files")
+	noVerify := fs.Bool("no-verify", false, "Skip decompression verification")
+	skipDecomp := fs.Bool("skip-decompression", false, "Skip decompression entirely")
+	parallelism := fs.Int("parallelism", 1, "Number of parallel benchmark jobs")
+	warmup := fs.Int("warmup", 0, "Untimed warm-up runs before each timed run")
+	cacheMode := fs.String("cache", benchmark.CacheModeNone, "Input page cache before each timed run: none, drop or warm")
+	stream := fs.Bool("stream", false, "Pipe data through codecs in memory instead of using temporary files")
+	timeout := fs.Duration("timeout", 0, "Maximum duration of each run, e.g. 30m (default: no limit)")
+	resume := fs.Bool("resume", false, "Skip runs that already have rows in the output CSV")
+	version := fs.Bool("version", false, "Show version information")
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	if *version {
+		fmt.Printf("compstat version %s (built %s)\n", Version, BuildTime)
+		os.Exit(0)
+	}
+
+	// Custom codecs must be registered before -param and -levels name them
+	if err := loadDefaultCodecs(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *codecConfig != "" {
+		if err := loadCodecConfig(*codecConfig); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	params := make(map[string]codec.ParamGrid)
+	for _, spec := range paramSpecs {
+		if err := codec.ParseParamSpec(params, spec); err != nil {
+			fmt.Printf("Error: -param: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *files == "" && *generate == "" && *planPath == "" {
+		fmt.Println("Error: -files, -generate or -plan is required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	// Parse inputs
+	fileList := make([]string, 0)
+	for _, f := range strings.Split(*files, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fileList = append(fileList, f)
+		}
+	}
+	generateList, err := corpus.ParseSpecs(*generate)
+	if err != nil {
+		fmt.Printf("Error: -generate: %v\n", err)
+		os.Exit(1)
+	}
+
+	recordSplit, err := benchmark.ParseRecordSplit(*records)
+	if err != nil {
+		fmt.Printf("Error: -records: %v\n", err)
+		os.Exit(1)
+	}
+
+	chunkSizeList, err := util.ParseSizes(*chunkSizes)
+	if err != nil {
+		fmt.Printf("Error: -chunk-size: %v\n", err)
+		os.Exit(1)
+	}
+
+	dictSampleList := make([]string, 0)
+	for _, f := range strings.Split(*dictSamples, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			dictSampleList = append(dictSampleList, f)
+		}
+	}
+	dictSizeList, err := util.ParseSizes(*dictSizes)
+	if err != nil {
+		fmt.Printf("Error: -dict-size: %v\n", err)
+		os.Exit(1)
+	}
+
+	codecList := make([]string, 0)
+	if *codecs == "" {
+		codecList = benchmark.DefaultCodecs(recordSplit)
+	} else {
+		for _, c := range strings.Split(*codecs, ",") {
+			codecList = append(codecList, strings.TrimSpace(c))
+		}
+	}
+
+	var levelMap map[string][]codec.Level
+	if *levels != "" {
+		var err error
+		levelMap, err = codec.ParseLevelSpec(*levels)
+		if err != nil {
+			fmt.Printf("Error: -levels: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	compThreadList, err := util.ParseThreads(*compThreads)
+	if err != nil {
+		fmt.Printf("Error: -compress-threads: %v\n", err)
+		os.Exit(1)
+	}
+	decompThreadList, err := util.ParseThreads(*decompThreads)
+	if err != nil {
+		fmt.Printf("Error: -decompress-threads: %v\n", err)
+		os.Exit(1)
+	}
+
+	tmpDirPath := *tmpDir
+	if tmpDirPath == "" {
+		tmpDirPath = filepath.Join(os.TempDir(), "compstat_tmp")
+	}
+
+	config := benchmark.Config{
+		Files:               fileList,
+		Generate:            generateList,
+		InputMode:           *inputMode,
+		Records:             recordSplit,
+		ChunkSizes:          chunkSizeList,
+		Codecs:              codecList,
+		Levels:              levelMap,
+		Params:              params,
+		DictSamples:         dictSampleList,
+		DictSizes:           dictSizeList,
+		CompressThreads:     compThreadList,
+		DecompressThreads:   decompThreadList,
+		Iterations:          *iterations,
+		TmpDir:              tmpDirPath,
+		OutputCSV:           *output,
+		OutputJSON:          *jsonOutput,
+		SummaryCSV:          *summaryOutput,
+		SummaryJSON:         *summaryJSONOutput,
+		CorpusSummary:       *corpusOutput,
+		VerifyDecompression: !*noVerify,
+		SkipDecompression:   *skipDecomp,
+		Parallelism:         *parallelism,
+		Streaming:           *stream,
+		WarmupRuns:          *warmup,
+		CacheMode:           *cacheMode,
+		Resume:              *resume,
+		Timeout:             *timeout,
+	}
+
+	if *planPath != "" {
+		plan, err := benchmark.LoadPlan(*planPath)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		planConfig, err := plan.Config()
+		if err != nil {
+			fmt.Printf("Error: plan %s: %v\n", *planPath, err)
+			os.Exit(1)
+		}
+		config = overridePlan(fs, planConfig, config)
+	}
+
+	runner, err := benchmark.NewRunner(config)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer runner.Close()
+
+	// The first interrupt cancels the run gracefully; restoring default signal
+	// handling afterwards lets a second one terminate immediately
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	runErr := runner.Run(ctx)
+	interrupted := errors.Is(runErr, context.Canceled)
+	if runErr != nil && !interrupted {
+		runner.Close()
+		fmt.Printf("Error: %v\n", runErr)
+		os.Exit(1)
+	}
+
+	if config.OutputJSON != "" {
+		if err := runner.WriteJSONSummary(); err != nil {
+			fmt.Printf("Warning: Failed to write JSON: %v\n", err)
+		}
+	}
+
+	if err := runner.WriteSummaries(); err != nil {
+		fmt.Printf("Warning: Failed to write summary: %v\n", err)
+	}
+
+	if interrupted {
+		runner.Close()
+		fmt.Printf("\n! Benchmark interrupted. Completed results: %s\n", config.OutputCSV)
+		fmt.Printf("  Total runs: %d\n", runner.ResultCount())
+		os.Exit(130)
+	}
+
+	fmt.Printf("\n✓ Benchmark complete! Results: %s\n", config.OutputCSV)
+	fmt.Printf("  Total runs: %d\n", runner.ResultCount())
+}
+
+// paramFlag collects repeated -param declarations. They are parsed once
+// custom codecs are registered, since they may name one.
+type paramFlag []string
+
+func (p *paramFlag) String() string {
+	return strings.Join(*p, " ")
+}
+
+func (p *paramFlag) Set(spec string) error {
+	*p = append(*p, spec)
+	return nil
+}
+
+// overridePlan copies the settings of run flags given explicitly on the
+// command line over the configuration loaded from a plan
+func overridePlan(fs *flag.FlagSet, plan, flags benchmark.Config) benchmark.Config {
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "files":
+			plan.Files = flags.Files
+		case "generate":
+			plan.Generate = flags.Generate
+		case "input-mode":
+			plan.InputMode = flags.InputMode
+		case "records":
+			plan.Records = flags.Records
+		case "chunk-size":
+			plan.ChunkSizes = flags.ChunkSizes
+		case "codecs":
+			plan.Codecs = flags.Codecs
+		case "levels":
+			plan.Levels = flags.Levels
+		case "param":
+			plan.Params = flags.Params
+		case "dict-samples":
+			plan.DictSamples = flags.DictSamples
+		case "dict-size":
+			plan.DictSizes = flags.DictSizes
+		case "compress-threads":
+			plan.CompressThreads = flags.CompressThreads
+		case "decompress-threads":
+			plan.DecompressThreads = flags.DecompressThreads
+		case "iterations":
+			plan.Iterations = flags.Iterations
+		case "tmpdir":
+			plan.TmpDir = flags.TmpDir
+		case "output":
+			plan.OutputCSV = flags.OutputCSV
+		case "json":
+			plan.OutputJSON = flags.OutputJSON
+		case "summary":
+			plan.SummaryCSV = flags.SummaryCSV
+		case "summary-json":
+			plan.SummaryJSON = flags.SummaryJSON
+		case "corpus-summary":
+			plan.CorpusSummary = flags.CorpusSummary
+		case "no-verify":
+			plan.VerifyDecompression = flags.VerifyDecompression
+		case "skip-decompression":
+			plan.SkipDecompression = flags.SkipDecompression
+		case "parallelism":
+			plan.Parallelism = flags.Parallelism
+		case "warmup":
+			plan.WarmupRuns = flags.WarmupRuns
+		case "cache":
+			plan.CacheMode = flags.CacheMode
+		case "stream":
+			plan.Streaming = flags.Streaming
+		case "timeout":
+			plan.Timeout = flags.Timeout
+		case "resume":
+			plan.Resume = flags.Resume
+		}
+	})
+	return plan
+}
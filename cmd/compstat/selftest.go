@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aomarai/compstat/internal/benchmark"
+	"github.com/aomarai/compstat/internal/codec"
+)
+
+// runSelfTest implements the selftest subcommand
+func runSelfTest(args []string) {
+	fs := flag.NewFlagSet("selftest", flag.ExitOnError)
+	codecs := fs.String("codecs", "", "Comma-separated codecs (default: all registered)")
+	codecConfig := fs.String("codec-config", "", "File (.yaml, .toml or .json) defining additional external codecs")
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	if err := loadDefaultCodecs(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *codecConfig != "" {
+		if err := loadCodecConfig(*codecConfig); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	names := make([]string, 0)
+	if *codecs == "" {
+		for name := range codec.Registry {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	} else {
+		for _, name := range strings.Split(*codecs, ",") {
+			names = append(names, strings.TrimSpace(name))
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "compstat_selftest")
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			fmt.Printf("warning: failed to remove %s: %v\n", dir, err)
+		}
+	}()
+
+	failed := 0
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "CODEC\tIO MODE\tRESULT")
+	for _, name := range names {
+		c, ok := codec.Registry[name]
+		if !ok {
+			_, _ = fmt.Fprintf(tw, "%s\t-\tunknown codec\n", name)
+			failed++
+			continue
+		}
+		result := "ok"
+		switch {
+		case !c.IsAvailable():
+			result = fmt.Sprintf("skipped: %s not found", c.Binary())
+		default:
+			if err := benchmark.SelfTest(context.Background(), c, dir); err != nil {
+				result = "FAIL: " + err.Error()
+				failed++
+			}
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", name, c.IOMode(), result)
+	}
+	_ = tw.Flush()
+
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -1,3 +1,17 @@
 module github.com/aomarai/compstat
 
-go 1.25
+go 1.25.0
+
+require (
+	github.com/andybalholm/brotli v1.2.6
+	github.com/dsnet/compress v0.0.1
+	github.com/klauspost/compress v1.20.1
+	github.com/pierrec/lz4/v4 v4.1.31
+	github.com/ulikunitz/xz v0.5.17
+)
+
+require (
+	github.com/BurntSushi/toml v1.6.0
+	golang.org/x/sys v0.43.0
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,377 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/aomarai/compstat/internal/benchmark"
+)
+
+// Metrics that Compare checks, named like the recommend weights
+const (
+	MetricRatio      = "ratio"
+	MetricCompress   = "compress"
+	MetricDecompress = "decompress"
+)
+
+// DefaultMetrics lists every metric Compare checks
+var DefaultMetrics = []string{MetricRatio, MetricCompress, MetricDecompress}
+
+// ParseMetrics parses a comma-separated metric list such as "ratio,decompress"
+func ParseMetrics(spec string) ([]string, error) {
+	metrics := make([]string, 0)
+	for _, part := range strings.Split(spec, ",") {
+		name := strings.TrimSpace(part)
+		switch name {
+		case "":
+			continue
+		case MetricRatio, MetricCompress, MetricDecompress:
+			metrics = append(metrics, name)
+		default:
+			return nil, fmt.Errorf("unknown metric %q (want ratio, compress or decompress)", name)
+		}
+	}
+	if len(metrics) == 0 {
+		return nil, fmt.Errorf("at least one metric is required")
+	}
+	return metrics, nil
+}
+
+// metricValue returns the value of a metric in a result, and whether higher
+// values are better
+func metricValue(metric string, r benchmark.Result) (value float64, higherBetter bool) {
+	switch metric {
+	case MetricCompress:
+		return r.CompressionSpeedMBs, true
+	case MetricDecompress:
+		return r.DecompressionSpeedMBs, true
+	}
+	return r.CompressionRatio, false
+}
+
+// CompareOptions controls what Compare reports as a regression
+type CompareOptions struct {
+	// Metrics lists the metrics to compare; empty means DefaultMetrics
+	Metrics []string
+	// Threshold is the relative change, e.g. 0.05 for 5%, by which a metric
+	// must get worse to count as a regression
+	Threshold float64
+	// Alpha is the significance level of the Welch t-test
+	Alpha float64
+}
+
+// Delta compares one metric of a configuration between two result sets
+type Delta struct {
+	Metric    string          `json:"metric"`
+	Baseline  benchmark.Stats `json:"baseline"`
+	Candidate benchmark.Stats `json:"candidate"`
+	// Change is the relative change of the mean from baseline to candidate
+	Change float64 `json:"change"`
+	// Worse is true if the change is in the unfavourable direction
+	Worse bool `json:"worse"`
+	// Tested is false when either side has fewer than two runs, which
+	// leaves nothing to test; PValue is then 1
+	Tested bool `json:"tested"`
+	// PValue is the two-sided p-value of Welch's t-test
+	PValue float64 `json:"p_value"`
+	// Significant is true if the test ran and PValue is below Alpha
+	Significant bool `json:"significant"`
+	// Regression is true if the metric got worse by more than Threshold and
+	// the change is significant, or untestable
+	Regression bool `json:"regression"`
+}
+
+// Comparison holds the deltas of one configuration found in both result sets
+type Comparison struct {
+	FilePath          string  `json:"file_path"`
+	FileHash          string  `json:"file_hash,omitempty"`
+	Algorithm         string  `json:"algorithm"`
+	Level             int     `json:"level"`
+	Params            string  `json:"params"`
+	DictionaryBytes   int64   `json:"dictionary_bytes"`
+	RecordFormat      string  `json:"record_format"`
+	CompressThreads   int     `json:"compress_threads"`
+	DecompressThreads int     `json:"decompress_threads"`
+	BaselineVersion   string  `json:"baseline_version"`
+	CandidateVersion  string  `json:"candidate_version"`
+	Deltas            []Delta `json:"deltas"`
+}
+
+// Regressed reports whether any metric of the configuration regressed
+func (c Comparison) Regressed() bool {
+	for _, d := range c.Deltas {
+		if d.Regression {
+			return true
+		}
+	}
+	return false
+}
+
+// CompareReport is the outcome of comparing two result sets
+type CompareReport struct {
+	Comparisons []Comparison `json:"comparisons"`
+	// MatchedOn is "file_hash" or "file_path", depending on whether every
+	// result recorded an input hash
+	MatchedOn string `json:"matched_on"`
+	// BaselineOnly and CandidateOnly count configurations missing from the
+	// other result set
+	BaselineOnly  int `json:"baseline_only"`
+	CandidateOnly int `json:"candidate_only"`
+}
+
+// Regressions returns the number of configurations with a regression
+func (r CompareReport) Regressions() int {
+	n := 0
+	for _, c := range r.Comparisons {
+		if c.Regressed() {
+			n++
+		}
+	}
+	return n
+}
+
+// compareKey identifies a configuration across result sets. Codec versions
+// are left out, since comparing them is the point.
+type compareKey struct {
+	input           string
+	algorithm       string
+	level           int
+	params          string
+	dictionaryBytes int64
+	recordFormat    string
+	compThreads     int
+	decompThreads   int
+}
+
+// compareGroup collects the runs of one configuration in one result set
+type compareGroup struct {
+	filePath string
+	versions []string
+	results  []benchmark.Result
+}
+
+// Compare matches the successful runs of baseline and candidate on input,
+// algorithm, level, parameters and threads, and tests each metric for a
+// change. Inputs are matched on their hash when every result has one, so
+// files may move between machines, and on their path otherwise.
+func Compare(baseline, candidate []benchmark.Result, opts CompareOptions) CompareReport {
+	metrics := opts.Metrics
+	if len(metrics) == 0 {
+		metrics = DefaultMetrics
+	}
+
+	report := CompareReport{Comparisons: make([]Comparison, 0), MatchedOn: "file_hash"}
+	for _, set := range [][]benchmark.Result{baseline, candidate} {
+		for _, res := range set {
+			if res.Succeeded() && res.FileHash == "" {
+				report.MatchedOn = "file_path"
+			}
+		}
+	}
+
+	base := groupForCompare(baseline, report.MatchedOn == "file_hash")
+	cand := groupForCompare(candidate, report.MatchedOn == "file_hash")
+	for key := range base {
+		if _, ok := cand[key]; !ok {
+			report.BaselineOnly++
+		}
+	}
+
+	for key, c := range cand {
+		b, ok := base[key]
+		if !ok {
+			report.CandidateOnly++
+			continue
+		}
+		cmp := Comparison{
+			FilePath:          c.filePath,
+			Algorithm:         key.algorithm,
+			Level:             key.level,
+			Params:            key.params,
+			DictionaryBytes:   key.dictionaryBytes,
+			RecordFormat:      key.recordFormat,
+			CompressThreads:   key.compThreads,
+			DecompressThreads: key.decompThreads,
+			BaselineVersion:   strings.Join(b.versions, ","),
+			CandidateVersion:  strings.Join(c.versions, ","),
+		}
+		if report.MatchedOn == "file_hash" {
+			cmp.FileHash = key.input
+		}
+		for _, metric := range metrics {
+			cmp.Deltas = append(cmp.Deltas, compareMetric(metric, b.results, c.results, opts))
+		}
+		report.Comparisons = append(report.Comparisons, cmp)
+	}
+
+	sort.Slice(report.Comparisons, func(i, j int) bool {
+		a, b := report.Comparisons[i], report.Comparisons[j]
+		if a.FilePath != b.FilePath {
+			return a.FilePath < b.FilePath
+		}
+		if a.Algorithm != b.Algorithm {
+			return a.Algorithm < b.Algorithm
+		}
+		if a.Level != b.Level {
+			return a.Level < b.Level
+		}
+		if a.Params != b.Params {
+			return a.Params < b.Params
+		}
+		if a.DictionaryBytes != b.DictionaryBytes {
+			return a.DictionaryBytes < b.DictionaryBytes
+		}
+		if a.RecordFormat != b.RecordFormat {
+			return a.RecordFormat < b.RecordFormat
+		}
+		if a.CompressThreads != b.CompressThreads {
+			return a.CompressThreads < b.CompressThreads
+		}
+		return a.DecompressThreads < b.DecompressThreads
+	})
+	return report
+}
+
+// groupForCompare groups the successful runs of a result set by
+// configuration
+func groupForCompare(results []benchmark.Result, byHash bool) map[compareKey]*compareGroup {
+	groups := make(map[compareKey]*compareGroup)
+	for _, res := range results {
+		if !res.Succeeded() {
+			continue
+		}
+		input := res.FilePath
+		if byHash {
+			input = res.FileHash
+		}
+		key := compareKey{input, res.Algorithm, res.Level, res.Params, res.DictionaryBytes, res.RecordFormat, res.CompressThreads, res.DecompressThreads}
+		g, ok := groups[key]
+		if !ok {
+			g = &compareGroup{filePath: res.FilePath}
+			groups[key] = g
+		}
+		if res.CodecVersion != "" && !contains(g.versions, res.CodecVersion) {
+			g.versions = append(g.versions, res.CodecVersion)
+		}
+		g.results = append(g.results, res)
+	}
+	return groups
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// compareMetric computes the delta of one metric between two groups of runs
+func compareMetric(metric string, baseline, candidate []benchmark.Result, opts CompareOptions) Delta {
+	var higherBetter bool
+	values := func(results []benchmark.Result) []float64 {
+		v := make([]float64, len(results))
+		for i, r := range results {
+			v[i], higherBetter = metricValue(metric, r)
+		}
+		return v
+	}
+	b, c := values(baseline), values(candidate)
+
+	d := Delta{
+		Metric:    metric,
+		Baseline:  benchmark.ComputeStats(b),
+		Candidate: benchmark.ComputeStats(c),
+		PValue:    WelchTTest(b, c),
+	}
+	d.Tested = !math.IsNaN(d.PValue)
+	if !d.Tested {
+		d.PValue = 1
+	}
+	if d.Baseline.Mean != 0 {
+		d.Change = (d.Candidate.Mean - d.Baseline.Mean) / d.Baseline.Mean
+	}
+	d.Worse = d.Change < 0 && higherBetter || d.Change > 0 && !higherBetter
+	d.Significant = d.Tested && d.PValue < opts.Alpha
+	// With a single run per side there is no variance to test against, so
+	// the threshold alone decides
+	d.Regression = d.Worse && math.Abs(d.Change) > opts.Threshold && (d.Significant || !d.Tested)
+	return d
+}
+
+// WelchTTest returns the two-sided p-value of Welch's t-test for a
+// difference between the means of a and b, or NaN if either has fewer than
+// two values. Samples without variance, such as deterministic compression
+// ratios, give 0 if the means differ and 1 otherwise.
+func WelchTTest(a, b []float64) float64 {
+	if len(a) < 2 || len(b) < 2 {
+		return math.NaN()
+	}
+	sa, sb := benchmark.ComputeStats(a), benchmark.ComputeStats(b)
+	va := sa.StdDev * sa.StdDev / float64(sa.N)
+	vb := sb.StdDev * sb.StdDev / float64(sb.N)
+	if va+vb == 0 {
+		if sa.Mean == sb.Mean {
+			return 1
+		}
+		return 0
+	}
+	t := (sa.Mean - sb.Mean) / math.Sqrt(va+vb)
+	// Welch–Satterthwaite degrees of freedom
+	df := (va + vb) * (va + vb) / (va*va/float64(sa.N-1) + vb*vb/float64(sb.N-1))
+	return regIncBeta(df/2, 0.5, df/(df+t*t))
+}
+
+// regIncBeta returns the regularized incomplete beta function I_x(a, b),
+// evaluated with a continued fraction
+func regIncBeta(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log(1-x))
+	// The continued fraction converges quickly for x below the mean
+	if x > (a+1)/(a+b+2) {
+		return 1 - front*betaFraction(b, a, 1-x)/b
+	}
+	return front * betaFraction(a, b, x) / a
+}
+
+// betaFraction evaluates the continued fraction of the incomplete beta
+// function with the modified Lentz method
+func betaFraction(a, b, x float64) float64 {
+	const tiny = 1e-300
+	clamp := func(v float64) float64 {
+		if math.Abs(v) < tiny {
+			return tiny
+		}
+		return v
+	}
+	c, d := 1.0, 1/clamp(1-(a+b)*x/(a+1))
+	h := d
+	for m := 1.0; m <= 300; m++ {
+		// Even step
+		num := m * (b - m) * x / ((a + 2*m - 1) * (a + 2*m))
+		d = 1 / clamp(1+num*d)
+		c = clamp(1 + num/c)
+		h *= d * c
+		// Odd step
+		num = -(a + m) * (a + b + m) * x / ((a + 2*m) * (a + 2*m + 1))
+		d = 1 / clamp(1+num*d)
+		c = clamp(1 + num/c)
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < 1e-12 {
+			break
+		}
+	}
+	return h
+}
@@ -0,0 +1,127 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/aomarai/compstat/internal/benchmark"
+)
+
+func TestWelchTTest(t *testing.T) {
+	// t = -1 with 8 degrees of freedom
+	p := WelchTTest([]float64{1, 2, 3, 4, 5}, []float64{2, 3, 4, 5, 6})
+	if math.Abs(p-0.3466) > 1e-3 {
+		t.Errorf("WelchTTest p = %.4f, want 0.3466", p)
+	}
+	if p := WelchTTest([]float64{10, 10.1, 9.9, 10}, []float64{20, 20.2, 19.8, 20}); p > 1e-6 {
+		t.Errorf("Expected a tiny p-value for well separated samples, got %g", p)
+	}
+	if p := WelchTTest([]float64{0.5, 0.5}, []float64{0.5, 0.5}); p != 1 {
+		t.Errorf("Identical constant samples: p = %g, want 1", p)
+	}
+	if p := WelchTTest([]float64{0.5, 0.5}, []float64{0.6, 0.6}); p != 0 {
+		t.Errorf("Different constant samples: p = %g, want 0", p)
+	}
+	if p := WelchTTest([]float64{1}, []float64{1, 2}); !math.IsNaN(p) {
+		t.Errorf("Expected NaN for a single value, got %g", p)
+	}
+}
+
+func runs(algorithm, hash, version string, ratio float64, compSpeeds ...float64) []benchmark.Result {
+	results := make([]benchmark.Result, 0, len(compSpeeds))
+	for i, speed := range compSpeeds {
+		results = append(results, benchmark.Result{
+			Algorithm:             algorithm,
+			Level:                 3,
+			CompressThreads:       1,
+			DecompressThreads:     1,
+			FilePath:              "/data/" + hash,
+			FileHash:              hash,
+			CodecVersion:          version,
+			CompressionRatio:      ratio,
+			CompressionSpeedMBs:   speed,
+			DecompressionSpeedMBs: 1000,
+			Iteration:             i + 1,
+			Status:                benchmark.StatusOK,
+		})
+	}
+	return results
+}
+
+func TestCompareFlagsSignificantRegressions(t *testing.T) {
+	baseline := append(runs("zstd", "aaa", "1.5.5", 0.30, 100, 101, 99, 100),
+		runs("xz", "aaa", "5.4", 0.25, 10, 10.2, 9.8)...)
+	baseline = append(baseline, runs("gzip", "aaa", "1.12", 0.35, 50)...)
+	candidate := append(runs("zstd", "aaa", "1.5.6", 0.30, 80, 81, 79, 80),
+		runs("xz", "aaa", "5.6", 0.25, 9.7, 10.1, 10)...)
+	candidate = append(candidate, runs("lz4", "aaa", "1.9", 0.5, 700)...)
+
+	report := Compare(baseline, candidate, CompareOptions{Threshold: 0.05, Alpha: 0.05})
+	if report.MatchedOn != "file_hash" {
+		t.Errorf("MatchedOn = %q, want file_hash", report.MatchedOn)
+	}
+	if report.BaselineOnly != 1 || report.CandidateOnly != 1 {
+		t.Errorf("BaselineOnly/CandidateOnly = %d/%d, want 1/1", report.BaselineOnly, report.CandidateOnly)
+	}
+	if len(report.Comparisons) != 2 {
+		t.Fatalf("Expected 2 comparisons, got %d", len(report.Comparisons))
+	}
+
+	xz, zstd := report.Comparisons[0], report.Comparisons[1]
+	if xz.Regressed() {
+		t.Errorf("xz: noise within the threshold reported as a regression: %+v", xz.Deltas)
+	}
+	if !zstd.Regressed() || report.Regressions() != 1 {
+		t.Fatalf("zstd: expected a compression speed regression, got %+v", zstd.Deltas)
+	}
+	if zstd.BaselineVersion != "1.5.5" || zstd.CandidateVersion != "1.5.6" {
+		t.Errorf("Versions = %s -> %s", zstd.BaselineVersion, zstd.CandidateVersion)
+	}
+	for _, d := range zstd.Deltas {
+		switch d.Metric {
+		case MetricRatio, MetricDecompress:
+			if d.Regression || d.Change != 0 {
+				t.Errorf("%s: unexpected delta %+v", d.Metric, d)
+			}
+		case MetricCompress:
+			if !d.Worse || !d.Significant || math.Abs(d.Change+0.2) > 1e-9 {
+				t.Errorf("compress: unexpected delta %+v", d)
+			}
+		}
+	}
+}
+
+func TestCompareRatioDirection(t *testing.T) {
+	baseline := runs("zstd", "aaa", "", 0.30, 100)
+	better := Compare(baseline, runs("zstd", "aaa", "", 0.20, 100), CompareOptions{Threshold: 0.05, Alpha: 0.05})
+	if better.Regressions() != 0 {
+		t.Errorf("A lower ratio is an improvement, got %+v", better.Comparisons[0].Deltas)
+	}
+
+	// Single runs cannot be tested, so the threshold alone decides
+	worse := Compare(baseline, runs("zstd", "aaa", "", 0.40, 100), CompareOptions{Threshold: 0.05, Alpha: 0.05, Metrics: []string{MetricRatio}})
+	if worse.Regressions() != 1 || worse.Comparisons[0].Deltas[0].Tested {
+		t.Errorf("Expected an untested ratio regression, got %+v", worse.Comparisons[0].Deltas)
+	}
+}
+
+func TestCompareFallsBackToFilePath(t *testing.T) {
+	baseline := runs("zstd", "aaa", "", 0.30, 100)
+	baseline[0].FileHash = ""
+	report := Compare(baseline, runs("zstd", "aaa", "", 0.30, 100), CompareOptions{})
+	if report.MatchedOn != "file_path" || len(report.Comparisons) != 1 {
+		t.Errorf("Expected a match on file path, got %+v", report)
+	}
+}
+
+func TestParseMetrics(t *testing.T) {
+	metrics, err := ParseMetrics("ratio, decompress")
+	if err != nil || len(metrics) != 2 || metrics[1] != MetricDecompress {
+		t.Errorf("ParseMetrics = %v, %v", metrics, err)
+	}
+	for _, spec := range []string{"", "speed"} {
+		if _, err := ParseMetrics(spec); err == nil {
+			t.Errorf("ParseMetrics(%q) succeeded, want error", spec)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aomarai/compstat/internal/benchmark"
+)
+
+// Candidate is a benchmark configuration considered for recommendation
+type Candidate struct {
+	benchmark.Summary
+	// Pareto is true if no other configuration has a lower ratio and higher
+	// compression and decompression speeds at the same time
+	Pareto bool
+	// Score is the weighted, normalized objective value in [0, 1]
+	Score float64
+}
+
+// Constraints limits which configurations are acceptable. Zero values mean
+// no limit.
+type Constraints struct {
+	MinCompressMBs   float64
+	MinDecompressMBs float64
+	MaxRatio         float64
+}
+
+// Weights sets the relative importance of each objective when ranking
+type Weights struct {
+	Ratio      float64
+	Compress   float64
+	Decompress float64
+}
+
+// DefaultWeights weighs all objectives equally
+var DefaultWeights = Weights{Ratio: 1, Compress: 1, Decompress: 1}
+
+// ParseWeights parses a weight list such as "ratio=2,decompress=1". Objectives
+// that are not mentioned get a weight of 0.
+func ParseWeights(spec string) (Weights, error) {
+	var w Weights
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return w, fmt.Errorf("invalid weight %q, expected name=value", part)
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || f < 0 {
+			return w, fmt.Errorf("invalid weight value %q", value)
+		}
+		switch strings.TrimSpace(name) {
+		case "ratio":
+			w.Ratio = f
+		case "compress":
+			w.Compress = f
+		case "decompress":
+			w.Decompress = f
+		default:
+			return w, fmt.Errorf("unknown weight %q (want ratio, compress or decompress)", name)
+		}
+	}
+	if w.Ratio+w.Compress+w.Decompress == 0 {
+		return w, fmt.Errorf("at least one weight must be positive")
+	}
+	return w, nil
+}
+
+// ParetoFrontier reports, for each summary, whether it lies on the Pareto
+// frontier of ratio (lower is better) versus compression and decompression
+// speed (higher is better), using mean values
+func ParetoFrontier(summaries []benchmark.Summary) []bool {
+	frontier := make([]bool, len(summaries))
+	for i, a := range summaries {
+		frontier[i] = true
+		for j, b := range summaries {
+			if i != j && dominates(b, a) {
+				frontier[i] = false
+				break
+			}
+		}
+	}
+	return frontier
+}
+
+// dominates reports whether a is at least as good as b on every objective
+// and strictly better on at least one
+func dominates(a, b benchmark.Summary) bool {
+	ar, ac, ad := a.CompressionRatio.Mean, a.CompressionSpeedMBs.Mean, a.DecompressionSpeedMBs.Mean
+	br, bc, bd := b.CompressionRatio.Mean, b.CompressionSpeedMBs.Mean, b.DecompressionSpeedMBs.Mean
+	if ar > br || ac < bc || ad < bd {
+		return false
+	}
+	return ar < br || ac > bc || ad > bd
+}
+
+// Satisfies reports whether s meets the constraints
+func (c Constraints) Satisfies(s benchmark.Summary) bool {
+	if c.MinCompressMBs > 0 && s.CompressionSpeedMBs.Mean < c.MinCompressMBs {
+		return false
+	}
+	if c.MinDecompressMBs > 0 && s.DecompressionSpeedMBs.Mean < c.MinDecompressMBs {
+		return false
+	}
+	if c.MaxRatio > 0 && s.CompressionRatio.Mean > c.MaxRatio {
+		return false
+	}
+	return true
+}
+
+// Recommend returns the configurations that satisfy the constraints, ranked
+// by weighted score. Each objective is normalized to [0, 1] across the
+// feasible set; speeds are compared on a log scale since they commonly span
+// several orders of magnitude between codecs.
+func Recommend(summaries []benchmark.Summary, constraints Constraints, weights Weights) []Candidate {
+	frontier := ParetoFrontier(summaries)
+
+	candidates := make([]Candidate, 0)
+	for i, s := range summaries {
+		if constraints.Satisfies(s) {
+			candidates = append(candidates, Candidate{Summary: s, Pareto: frontier[i]})
+		}
+	}
+	if len(candidates) == 0 {
+		return candidates
+	}
+
+	ratio := normalizer(candidates, func(c Candidate) float64 { return -c.CompressionRatio.Mean })
+	comp := normalizer(candidates, func(c Candidate) float64 { return logSpeed(c.CompressionSpeedMBs.Mean) })
+	decomp := normalizer(candidates, func(c Candidate) float64 { return logSpeed(c.DecompressionSpeedMBs.Mean) })
+
+	total := weights.Ratio + weights.Compress + weights.Decompress
+	for i := range candidates {
+		c := candidates[i]
+		candidates[i].Score = (weights.Ratio*ratio(c) + weights.Compress*comp(c) + weights.Decompress*decomp(c)) / total
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	return candidates
+}
+
+func logSpeed(mbs float64) float64 {
+	if mbs <= 0 {
+		return 0
+	}
+	return math.Log10(mbs + 1)
+}
+
+// normalizer returns a function mapping value(c) linearly onto [0, 1], where
+// 1 is the best value among candidates
+func normalizer(candidates []Candidate, value func(Candidate) float64) func(Candidate) float64 {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, c := range candidates {
+		v := value(c)
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+	return func(c Candidate) float64 {
+		if hi == lo {
+			return 1
+		}
+		return (value(c) - lo) / (hi - lo)
+	}
+}
@@ -0,0 +1,74 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/aomarai/compstat/internal/benchmark"
+)
+
+func summary(algorithm string, ratio, comp, decomp float64) benchmark.Summary {
+	return benchmark.Summary{
+		Algorithm:             algorithm,
+		CompressionRatio:      benchmark.Stats{N: 1, Mean: ratio},
+		CompressionSpeedMBs:   benchmark.Stats{N: 1, Mean: comp},
+		DecompressionSpeedMBs: benchmark.Stats{N: 1, Mean: decomp},
+	}
+}
+
+func TestParetoFrontier(t *testing.T) {
+	summaries := []benchmark.Summary{
+		summary("lz4", 0.50, 700, 3000),
+		summary("zstd", 0.35, 400, 1200),
+		summary("gzip", 0.40, 50, 300), // dominated by zstd
+		summary("xz", 0.25, 5, 100),
+	}
+
+	got := ParetoFrontier(summaries)
+	want := []bool{true, true, false, true}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("%s: expected pareto=%v, got %v", summaries[i].Algorithm, want[i], got[i])
+		}
+	}
+}
+
+func TestRecommendAppliesConstraintsAndWeights(t *testing.T) {
+	summaries := []benchmark.Summary{
+		summary("lz4", 0.50, 700, 3000),
+		summary("zstd", 0.35, 400, 1200),
+		summary("xz", 0.25, 5, 100),
+	}
+
+	got := Recommend(summaries, Constraints{MinDecompressMBs: 500}, Weights{Ratio: 1})
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 feasible candidates, got %d", len(got))
+	}
+	if got[0].Algorithm != "zstd" {
+		t.Errorf("Expected zstd to rank first on ratio, got %s", got[0].Algorithm)
+	}
+
+	got = Recommend(summaries, Constraints{}, Weights{Decompress: 1})
+	if got[0].Algorithm != "lz4" || got[0].Score != 1 {
+		t.Errorf("Expected lz4 to rank first with score 1, got %s (%f)", got[0].Algorithm, got[0].Score)
+	}
+
+	if got := Recommend(summaries, Constraints{MaxRatio: 0.1}, DefaultWeights); len(got) != 0 {
+		t.Errorf("Expected no feasible candidates, got %d", len(got))
+	}
+}
+
+func TestParseWeights(t *testing.T) {
+	w, err := ParseWeights("ratio=2, decompress=0.5")
+	if err != nil {
+		t.Fatalf("ParseWeights failed: %v", err)
+	}
+	if w.Ratio != 2 || w.Compress != 0 || w.Decompress != 0.5 {
+		t.Errorf("Unexpected weights: %+v", w)
+	}
+
+	for _, spec := range []string{"speed=1", "ratio", "ratio=-1", "ratio=0"} {
+		if _, err := ParseWeights(spec); err == nil {
+			t.Errorf("Expected error for %q", spec)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package benchmark
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// CorpusSummary totals one configuration over every input file, as if the
+// files were one corpus. Each file contributes the mean of its successful
+// iterations; speeds are weighted by file size.
+type CorpusSummary struct {
+	Algorithm         string `json:"algorithm"`
+	Level             int    `json:"level"`
+	Params            string `json:"params"`
+	CodecVersion      string `json:"codec_version"`
+	DictionaryBytes   int64  `json:"dictionary_bytes"`
+	RecordFormat      string `json:"record_format"`
+	CompressThreads   int    `json:"compress_threads"`
+	DecompressThreads int    `json:"decompress_threads"`
+	Files             int    `json:"files"`
+	// FailedFiles counts files without a successful run, which are left
+	// out of the totals
+	FailedFiles int `json:"failed_files"`
+
+	BytesIn               int64   `json:"bytes_in"`
+	BytesOut              int64   `json:"bytes_out"`
+	CompressionRatio      float64 `json:"compression_ratio"`
+	CompressionTimeS      float64 `json:"compression_time_s"`
+	DecompressionTimeS    float64 `json:"decompression_time_s"`
+	CompressionSpeedMBs   float64 `json:"compression_speed_mbs"`
+	DecompressionSpeedMBs float64 `json:"decompression_speed_mbs"`
+}
+
+// configKey identifies a configuration independently of the input file
+type configKey struct {
+	algorithm         string
+	level             int
+	params            string
+	codecVersion      string
+	dictionaryBytes   int64
+	recordFormat      string
+	compressThreads   int
+	decompressThreads int
+}
+
+// fileTotals accumulates the successful iterations of one file
+type fileTotals struct {
+	runs       int
+	bytesIn    int64
+	bytesOut   float64
+	compTime   float64
+	decompRuns int
+	decompTime float64
+}
+
+// AggregateCorpus totals results per configuration across input files
+func AggregateCorpus(results []Result) []CorpusSummary {
+	groups := make(map[configKey]map[string]*fileTotals)
+	keys := make([]configKey, 0)
+	for _, res := range results {
+		key := configKey{res.Algorithm, res.Level, res.Params, res.CodecVersion, res.DictionaryBytes, res.RecordFormat, res.CompressThreads, res.DecompressThreads}
+		files, ok := groups[key]
+		if !ok {
+			files = make(map[string]*fileTotals)
+			groups[key] = files
+			keys = append(keys, key)
+		}
+		totals, ok := files[res.FilePath]
+		if !ok {
+			totals = &fileTotals{}
+			files[res.FilePath] = totals
+		}
+		if !res.Succeeded() {
+			continue
+		}
+		totals.runs++
+		totals.bytesIn = res.UncompressedBytes
+		totals.bytesOut += float64(res.CompressedBytes)
+		totals.compTime += res.CompressionTimeS
+		if res.DecompressionTimeS > 0 {
+			totals.decompRuns++
+			totals.decompTime += res.DecompressionTimeS
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.algorithm != b.algorithm {
+			return a.algorithm < b.algorithm
+		}
+		if a.level != b.level {
+			return a.level < b.level
+		}
+		if a.params != b.params {
+			return a.params < b.params
+		}
+		if a.codecVersion != b.codecVersion {
+			return a.codecVersion < b.codecVersion
+		}
+		if a.dictionaryBytes != b.dictionaryBytes {
+			return a.dictionaryBytes < b.dictionaryBytes
+		}
+		if a.recordFormat != b.recordFormat {
+			return a.recordFormat < b.recordFormat
+		}
+		if a.compressThreads != b.compressThreads {
+			return a.compressThreads < b.compressThreads
+		}
+		return a.decompressThreads < b.decompressThreads
+	})
+
+	summaries := make([]CorpusSummary, 0, len(keys))
+	for _, key := range keys {
+		s := CorpusSummary{
+			Algorithm:         key.algorithm,
+			Level:             key.level,
+			Params:            key.params,
+			CodecVersion:      key.codecVersion,
+			DictionaryBytes:   key.dictionaryBytes,
+			RecordFormat:      key.recordFormat,
+			CompressThreads:   key.compressThreads,
+			DecompressThreads: key.decompressThreads,
+		}
+		var bytesOut float64
+		var decompBytes int64
+		for _, totals := range groups[key] {
+			s.Files++
+			if totals.runs == 0 {
+				s.FailedFiles++
+				continue
+			}
+			s.BytesIn += totals.bytesIn
+			bytesOut += totals.bytesOut / float64(totals.runs)
+			s.CompressionTimeS += totals.compTime / float64(totals.runs)
+			if totals.decompRuns > 0 {
+				decompBytes += totals.bytesIn
+				s.DecompressionTimeS += totals.decompTime / float64(totals.decompRuns)
+			}
+		}
+		s.BytesOut = int64(bytesOut + 0.5)
+		if s.BytesIn > 0 {
+			s.CompressionRatio = float64(s.BytesOut) / float64(s.BytesIn)
+		}
+		if s.CompressionTimeS > 0 {
+			s.CompressionSpeedMBs = bytesToMB(s.BytesIn) / s.CompressionTimeS
+		}
+		if s.DecompressionTimeS > 0 {
+			s.DecompressionSpeedMBs = bytesToMB(decompBytes) / s.DecompressionTimeS
+		}
+		summaries = append(summaries, s)
+	}
+	return summaries
+}
+
+var corpusSummaryHeader = []string{
+	"algorithm", "level", "params", "codec_version", "dictionary_bytes", "record_format", "compress_threads", "decompress_threads",
+	"files", "failed_files", "bytes_in", "bytes_out", "compression_ratio",
+	"compression_time_s", "decompression_time_s", "compression_speed_mbs", "decompression_speed_mbs",
+}
+
+// WriteCorpusSummary writes corpus summaries to path, as JSON if the path
+// ends in .json and as CSV otherwise
+func WriteCorpusSummary(path string, summaries []CorpusSummary) error {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		data, err := json.MarshalIndent(summaries, "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, data, 0644)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	if err := w.Write(corpusSummaryHeader); err != nil {
+		_ = f.Close()
+		return err
+	}
+	for _, s := range summaries {
+		row := []string{
+			s.Algorithm,
+			strconv.Itoa(s.Level),
+			s.Params,
+			s.CodecVersion,
+			strconv.FormatInt(s.DictionaryBytes, 10),
+			s.RecordFormat,
+			strconv.Itoa(s.CompressThreads),
+			strconv.Itoa(s.DecompressThreads),
+			strconv.Itoa(s.Files),
+			strconv.Itoa(s.FailedFiles),
+			strconv.FormatInt(s.BytesIn, 10),
+			strconv.FormatInt(s.BytesOut, 10),
+			fmt.Sprintf("%.4f", s.CompressionRatio),
+			fmt.Sprintf("%.4f", s.CompressionTimeS),
+			fmt.Sprintf("%.4f", s.DecompressionTimeS),
+			fmt.Sprintf("%.4f", s.CompressionSpeedMBs),
+			fmt.Sprintf("%.4f", s.DecompressionSpeedMBs),
+		}
+		if err := w.Write(row); err != nil {
+			_ = f.Close()
+			return err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// PrintCorpusTable writes a human-readable table of corpus summaries to w
+func PrintCorpusTable(w io.Writer, summaries []CorpusSummary) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "ALGORITHM\tLEVEL\tPARAMS\tTHREADS\tFILES\tBYTES IN\tBYTES OUT\tRATIO\tCOMP MB/s\tDECOMP MB/s")
+	for _, s := range summaries {
+		files := strconv.Itoa(s.Files)
+		if s.FailedFiles > 0 {
+			files += fmt.Sprintf(" (%d failed)", s.FailedFiles)
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%s\t%d/%d\t%s\t%d\t%d\t%.4f\t%.2f\t%s\n",
+			s.Algorithm, s.Level, ParamsLabel(s.Params, s.DictionaryBytes, s.RecordFormat), s.CompressThreads, s.DecompressThreads,
+			files, s.BytesIn, s.BytesOut, s.CompressionRatio, s.CompressionSpeedMBs, speedOrDash(s.DecompressionSpeedMBs))
+	}
+	_ = tw.Flush()
+}
+
+func speedOrDash(speed float64) string {
+	if speed == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", speed)
+}
@@ -0,0 +1,50 @@
+package benchmark
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestAggregateCorpus(t *testing.T) {
+	results := []Result{
+		// small.txt: two iterations, mean 50 bytes out, 0.5s to compress
+		{FilePath: "small.txt", Algorithm: "zstd", Level: 3, UncompressedBytes: 100, CompressedBytes: 40, CompressionTimeS: 0.4, DecompressionTimeS: 0.1, Status: StatusOK},
+		{FilePath: "small.txt", Algorithm: "zstd", Level: 3, UncompressedBytes: 100, CompressedBytes: 60, CompressionTimeS: 0.6, DecompressionTimeS: 0.1, Status: StatusOK},
+		{FilePath: "big.txt", Algorithm: "zstd", Level: 3, UncompressedBytes: 900, CompressedBytes: 150, CompressionTimeS: 1.5, DecompressionTimeS: 0.4, Status: StatusOK},
+		{FilePath: "broken.txt", Algorithm: "zstd", Level: 3, UncompressedBytes: 10, Status: StatusFailed},
+		{FilePath: "small.txt", Algorithm: "xz", Level: 6, UncompressedBytes: 100, CompressedBytes: 30, CompressionTimeS: 1, Status: StatusOK},
+	}
+
+	summaries := AggregateCorpus(results)
+	if len(summaries) != 2 || summaries[0].Algorithm != "xz" || summaries[1].Algorithm != "zstd" {
+		t.Fatalf("Unexpected summaries %+v", summaries)
+	}
+
+	s := summaries[1]
+	if s.Files != 3 || s.FailedFiles != 1 {
+		t.Errorf("Expected 3 files with 1 failed, got %d and %d", s.Files, s.FailedFiles)
+	}
+	if s.BytesIn != 1000 || s.BytesOut != 200 || s.CompressionRatio != 0.2 {
+		t.Errorf("Expected 1000 -> 200 bytes, got %d -> %d (%.4f)", s.BytesIn, s.BytesOut, s.CompressionRatio)
+	}
+	if math.Abs(s.CompressionTimeS-2) > 1e-9 || math.Abs(s.DecompressionTimeS-0.5) > 1e-9 {
+		t.Errorf("Expected 2s/0.5s, got %v/%v", s.CompressionTimeS, s.DecompressionTimeS)
+	}
+	if want := bytesToMB(1000) / 2; math.Abs(s.CompressionSpeedMBs-want) > 1e-12 {
+		t.Errorf("Expected size-weighted speed %v, got %v", want, s.CompressionSpeedMBs)
+	}
+
+	if xz := summaries[0]; xz.DecompressionSpeedMBs != 0 || xz.Files != 1 {
+		t.Errorf("Expected no decompression speed for xz, got %+v", xz)
+	}
+}
+
+func TestWriteCorpusSummaryFormats(t *testing.T) {
+	summaries := AggregateCorpus([]Result{{FilePath: "a", Algorithm: "zstd", Level: 1, UncompressedBytes: 10, CompressedBytes: 5, CompressionTimeS: 1, Status: StatusOK}})
+	for _, name := range []string{"corpus.csv", "corpus.json"} {
+		if err := WriteCorpusSummary(filepath.Join(t.TempDir(), name), summaries); err != nil {
+			t.Errorf("WriteCorpusSummary(%s) failed: %v", name, err)
+		}
+	}
+}
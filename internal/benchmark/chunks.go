@@ -0,0 +1,91 @@
+package benchmark
+
+import (
+	"bytes"
+	"context"
+	"os"
+
+	"github.com/aomarai/compstat/internal/codec"
+	"github.com/aomarai/compstat/internal/util"
+)
+
+// recordSplits returns the record splits to sweep: the configured record
+// mode, or one fixed-size split per chunk size. Chunk sweeps always include
+// the whole file, which is the baseline for the per-chunk overhead.
+func (r *Runner) recordSplits() []RecordSplit {
+	if len(r.config.ChunkSizes) == 0 {
+		return []RecordSplit{r.config.Records}
+	}
+	splits := []RecordSplit{{}}
+	for _, size := range r.config.ChunkSizes {
+		if size > 0 {
+			splits = append(splits, RecordSplit{Kind: RecordsFixed, Size: size})
+		}
+	}
+	return splits
+}
+
+// execChunks codes each chunk by running the codec's binary once, through
+// stdin/stdout when the codec can stream and through temporary files
+// otherwise. Its per-chunk timings include process start-up.
+type execChunks struct {
+	ctx        context.Context
+	c          codec.Codec
+	decompress bool
+	level      int
+	threads    int
+	params     codec.Params
+	dict       string
+	// in and out are the temporary files of codecs that cannot stream
+	in, out string
+}
+
+// execCoders codes j's chunks with the codec's binary, using compOut and
+// decompOut as temporary files
+func execCoders(ctx context.Context, c codec.Codec, j job, compOut, decompOut string) recordCoders {
+	dict := j.dict.dictPath()
+	return recordCoders{
+		compressor: func() (codec.RecordCoder, error) {
+			return &execChunks{ctx: ctx, c: c, level: j.level, threads: j.compressThreads,
+				params: j.params, dict: dict, in: decompOut, out: compOut}, nil
+		},
+		decompressor: func() (codec.RecordCoder, error) {
+			return &execChunks{ctx: ctx, c: c, decompress: true, threads: j.decompressThreads,
+				params: j.params, dict: dict, in: compOut, out: decompOut}, nil
+		},
+	}
+}
+
+func (e *execChunks) Append(dst, src []byte) ([]byte, error) {
+	if sc, ok := e.c.(codec.StreamingCodec); ok {
+		args := codec.StreamCompressArgs(sc, e.level, e.threads, e.params, e.dict)
+		if e.decompress {
+			args = codec.StreamDecompressArgs(sc, e.threads, e.params, e.dict)
+		}
+		buf := bytes.NewBuffer(dst)
+		_, err := util.RunPipe(e.ctx, e.c.Binary(), args, bytes.NewReader(src), buf)
+		return buf.Bytes(), err
+	}
+
+	if err := os.WriteFile(e.in, src, 0644); err != nil {
+		return dst, err
+	}
+	removeIfExists(e.out)
+	var err error
+	if e.decompress {
+		_, err = decompress(e.ctx, e.c, e.threads, e.params, e.dict, e.in, e.out)
+	} else {
+		_, err = compress(e.ctx, e.c, e.level, e.threads, e.params, e.dict, e.in, e.out)
+	}
+	if err != nil {
+		return dst, err
+	}
+	out, err := os.ReadFile(e.out)
+	return append(dst, out...), err
+}
+
+func (e *execChunks) Close() error {
+	removeIfExists(e.in)
+	removeIfExists(e.out)
+	return nil
+}
@@ -0,0 +1,79 @@
+package benchmark
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aomarai/compstat/internal/codec"
+)
+
+func TestRecordSplitsFromChunkSizes(t *testing.T) {
+	for _, sizes := range [][]int64{{0, 65536}, {65536}, {65536, 0}} {
+		runner := &Runner{config: Config{ChunkSizes: sizes}}
+		splits := runner.recordSplits()
+		if len(splits) != 2 || splits[0] != (RecordSplit{}) || splits[1].String() != "fixed:64KiB" {
+			t.Errorf("%v: expected the whole file and 64KiB chunks, got %+v", sizes, splits)
+		}
+	}
+
+	runner := &Runner{}
+
+	runner.config = Config{Records: RecordSplit{Kind: RecordsLines}}
+	if splits := runner.recordSplits(); len(splits) != 1 || splits[0].Kind != RecordsLines {
+		t.Errorf("Expected the record mode split, got %+v", splits)
+	}
+}
+
+func TestChunkedBenchmark(t *testing.T) {
+	// cat copies data unchanged and cannot stream, so its chunks go through
+	// temporary files
+	cat, err := codec.NewExternalCodec(codec.ExternalDefinition{
+		Name:       "cat",
+		Binary:     "cat",
+		Levels:     "1",
+		Stdout:     true,
+		Compress:   []string{"{input}"},
+		Decompress: []string{"{input}"},
+	})
+	if err != nil {
+		t.Fatalf("NewExternalCodec failed: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		codec  codec.Codec
+		status string
+	}{
+		{"in-process", &fakeCodec{}, StatusOK},
+		{"corrupt", &fakeCodec{corrupt: true}, StatusVerifyFailed},
+		{"exec files", cat, StatusOK},
+		{"exec stream", &codec.ZstdCodec{}, StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.codec.IsAvailable() {
+				t.Skipf("%s not available", tt.codec.Binary())
+			}
+			runner, input := newTestRunner(t, Config{ChunkSizes: []int64{4}})
+			j := job{filePath: input, codec: tt.codec, level: tt.codec.Levels()[0], records: RecordSplit{Kind: RecordsFixed, Size: 4},
+				iteration: 1, compressThreads: 1, decompressThreads: 1}
+			result := runner.runSingleBenchmark(context.Background(), j)
+			if result == nil {
+				t.Fatal("Expected a result row")
+			}
+			if result.Status != tt.status {
+				t.Fatalf("Expected status %s, got %s (%s)", tt.status, result.Status, result.Error)
+			}
+			// "hello compstat" is 14 bytes, so four chunks
+			if result.Records != 4 || result.RecordFormat != "fixed:4" || result.UncompressedBytes != 14 {
+				t.Errorf("Unexpected chunk totals %d %q %d", result.Records, result.RecordFormat, result.UncompressedBytes)
+			}
+			if tt.status == StatusOK && !result.Verified {
+				t.Errorf("Expected a verified run, got %+v", result)
+			}
+			if result.CompressionRecordP99Us <= 0 || (tt.status == StatusOK && result.DecompressionRecordP99Us <= 0) {
+				t.Errorf("Expected chunk latencies, got %+v", result)
+			}
+		})
+	}
+}
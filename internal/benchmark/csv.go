@@ -0,0 +1,240 @@
+package benchmark
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// csvColumn maps one CSV column to a Result field
+type csvColumn struct {
+	name   string
+	format func(r *Result) string
+	parse  func(r *Result, s string) error
+}
+
+func stringColumn(name string, field func(*Result) *string) csvColumn {
+	return csvColumn{
+		name:   name,
+		format: func(r *Result) string { return *field(r) },
+		parse: func(r *Result, s string) error {
+			*field(r) = s
+			return nil
+		},
+	}
+}
+
+func intColumn(name string, field func(*Result) *int) csvColumn {
+	return csvColumn{
+		name:   name,
+		format: func(r *Result) string { return strconv.Itoa(*field(r)) },
+		parse: func(r *Result, s string) (err error) {
+			*field(r), err = strconv.Atoi(s)
+			return err
+		},
+	}
+}
+
+func int64Column(name string, field func(*Result) *int64) csvColumn {
+	return csvColumn{
+		name:   name,
+		format: func(r *Result) string { return strconv.FormatInt(*field(r), 10) },
+		parse: func(r *Result, s string) (err error) {
+			*field(r), err = strconv.ParseInt(s, 10, 64)
+			return err
+		},
+	}
+}
+
+func floatColumn(name string, precision int, field func(*Result) *float64) csvColumn {
+	return csvColumn{
+		name:   name,
+		format: func(r *Result) string { return strconv.FormatFloat(*field(r), 'f', precision, 64) },
+		parse: func(r *Result, s string) (err error) {
+			*field(r), err = strconv.ParseFloat(s, 64)
+			return err
+		},
+	}
+}
+
+func boolColumn(name string, field func(*Result) *bool) csvColumn {
+	return csvColumn{
+		name:   name,
+		format: func(r *Result) string { return strconv.FormatBool(*field(r)) },
+		parse: func(r *Result, s string) (err error) {
+			*field(r), err = strconv.ParseBool(s)
+			return err
+		},
+	}
+}
+
+// resultColumns defines the CSV schema, in column order
+var resultColumns = []csvColumn{
+	stringColumn("run_id", func(r *Result) *string { return &r.RunID }),
+	stringColumn("algorithm", func(r *Result) *string { return &r.Algorithm }),
+	intColumn("level", func(r *Result) *int { return &r.Level }),
+	intColumn("compress_threads", func(r *Result) *int { return &r.CompressThreads }),
+	intColumn("decompress_threads", func(r *Result) *int { return &r.DecompressThreads }),
+	stringColumn("file_path", func(r *Result) *string { return &r.FilePath }),
+	int64Column("uncompressed_bytes", func(r *Result) *int64 { return &r.UncompressedBytes }),
+	int64Column("compressed_bytes", func(r *Result) *int64 { return &r.CompressedBytes }),
+	floatColumn("compression_ratio", 4, func(r *Result) *float64 { return &r.CompressionRatio }),
+	floatColumn("compression_time_s", 3, func(r *Result) *float64 { return &r.CompressionTimeS }),
+	floatColumn("decompression_time_s", 3, func(r *Result) *float64 { return &r.DecompressionTimeS }),
+	floatColumn("compression_speed_mbs", 2, func(r *Result) *float64 { return &r.CompressionSpeedMBs }),
+	floatColumn("decompression_speed_mbs", 2, func(r *Result) *float64 { return &r.DecompressionSpeedMBs }),
+	floatColumn("compression_max_rss_mb", 2, func(r *Result) *float64 { return &r.CompressionMaxRSSMB }),
+	floatColumn("decompression_max_rss_mb", 2, func(r *Result) *float64 { return &r.DecompressionMaxRSSMB }),
+	boolColumn("verified", func(r *Result) *bool { return &r.Verified }),
+	intColumn("iteration", func(r *Result) *int { return &r.Iteration }),
+	floatColumn("compression_user_s", 3, func(r *Result) *float64 { return &r.CompressionUserS }),
+	floatColumn("compression_sys_s", 3, func(r *Result) *float64 { return &r.CompressionSysS }),
+	int64Column("compression_voluntary_ctx_switches", func(r *Result) *int64 { return &r.CompressionVolCtxSwitches }),
+	int64Column("compression_involuntary_ctx_switches", func(r *Result) *int64 { return &r.CompressionInvolCtxSwitches }),
+	int64Column("compression_minor_faults", func(r *Result) *int64 { return &r.CompressionMinorFaults }),
+	int64Column("compression_major_faults", func(r *Result) *int64 { return &r.CompressionMajorFaults }),
+	floatColumn("decompression_user_s", 3, func(r *Result) *float64 { return &r.DecompressionUserS }),
+	floatColumn("decompression_sys_s", 3, func(r *Result) *float64 { return &r.DecompressionSysS }),
+	int64Column("decompression_voluntary_ctx_switches", func(r *Result) *int64 { return &r.DecompressionVolCtxSwitches }),
+	int64Column("decompression_involuntary_ctx_switches", func(r *Result) *int64 { return &r.DecompressionInvolCtxSwitches }),
+	int64Column("decompression_minor_faults", func(r *Result) *int64 { return &r.DecompressionMinorFaults }),
+	int64Column("decompression_major_faults", func(r *Result) *int64 { return &r.DecompressionMajorFaults }),
+	intColumn("warmup_runs", func(r *Result) *int { return &r.WarmupRuns }),
+	stringColumn("cache_state", func(r *Result) *string { return &r.CacheState }),
+	stringColumn("status", func(r *Result) *string { return &r.Status }),
+	stringColumn("error", func(r *Result) *string { return &r.Error }),
+	stringColumn("params", func(r *Result) *string { return &r.Params }),
+	stringColumn("codec_version", func(r *Result) *string { return &r.CodecVersion }),
+	int64Column("dictionary_bytes", func(r *Result) *int64 { return &r.DictionaryBytes }),
+	floatColumn("dictionary_train_s", 3, func(r *Result) *float64 { return &r.DictionaryTrainS }),
+	stringColumn("record_format", func(r *Result) *string { return &r.RecordFormat }),
+	int64Column("records", func(r *Result) *int64 { return &r.Records }),
+	floatColumn("compression_record_p50_us", 2, func(r *Result) *float64 { return &r.CompressionRecordP50Us }),
+	floatColumn("compression_record_p99_us", 2, func(r *Result) *float64 { return &r.CompressionRecordP99Us }),
+	floatColumn("compression_records_per_s", 1, func(r *Result) *float64 { return &r.CompressionRecordsPerS }),
+	floatColumn("decompression_record_p50_us", 2, func(r *Result) *float64 { return &r.DecompressionRecordP50Us }),
+	floatColumn("decompression_record_p99_us", 2, func(r *Result) *float64 { return &r.DecompressionRecordP99Us }),
+	floatColumn("decompression_records_per_s", 1, func(r *Result) *float64 { return &r.DecompressionRecordsPerS }),
+	stringColumn("file_hash", func(r *Result) *string { return &r.FileHash }),
+}
+
+// csvHeader returns the CSV header row
+func csvHeader() []string {
+	header := make([]string, len(resultColumns))
+	for i, col := range resultColumns {
+		header[i] = col.name
+	}
+	return header
+}
+
+// csvRow formats a result as a CSV row
+func csvRow(result Result) []string {
+	row := make([]string, len(resultColumns))
+	for i, col := range resultColumns {
+		row[i] = col.format(&result)
+	}
+	return row
+}
+
+// ReadResultsCSV parses results written by the Runner. Columns are matched by
+// name, so files from older versions with fewer columns still load; unknown
+// columns are ignored.
+func ReadResultsCSV(r io.Reader) ([]Result, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]csvColumn, len(resultColumns))
+	for _, col := range resultColumns {
+		byName[col.name] = col
+	}
+	columns := make([]*csvColumn, len(header))
+	for i, name := range header {
+		if col, ok := byName[strings.TrimSpace(name)]; ok {
+			columns[i] = &col
+		}
+	}
+
+	results := make([]Result, 0)
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var result Result
+		for i, value := range row {
+			if i >= len(columns) || columns[i] == nil || value == "" {
+				continue
+			}
+			if err := columns[i].parse(&result, value); err != nil {
+				return nil, fmt.Errorf("line %d, column %s: %w", line, columns[i].name, err)
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// LoadResults reads results from a CSV or JSON file written by the Runner,
+// choosing the format from the file extension
+func LoadResults(path string) ([]Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func(f *os.File) {
+		err := f.Close()
+		if err != nil {
+			fmt.Printf("warning: failed to close file: %v\n", err)
+		}
+	}(f)
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		results, err := readResultsJSON(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		return results, nil
+	}
+
+	results, err := ReadResultsCSV(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return results, nil
+}
+
+// readResultsJSON accepts both the ResultsFile layout and the bare array of
+// results written by older versions
+func readResultsJSON(r io.Reader) ([]Result, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '[' {
+		var results []Result
+		err := json.Unmarshal(raw, &results)
+		return results, err
+	}
+	var file ResultsFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, err
+	}
+	return file.Results, nil
+}
@@ -0,0 +1,74 @@
+package benchmark
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+func TestCSVRoundTrip(t *testing.T) {
+	want := Result{
+		RunID:                  "1_file.bin_zstd_3_1",
+		Algorithm:              "zstd",
+		Level:                  3,
+		CompressThreads:        4,
+		DecompressThreads:      4,
+		FilePath:               "/path/to/file.bin",
+		UncompressedBytes:      1000000,
+		CompressedBytes:        500000,
+		CompressionRatio:       0.5,
+		CompressionTimeS:       1.5,
+		DecompressionTimeS:     0.5,
+		CompressionSpeedMBs:    666.67,
+		DecompressionSpeedMBs:  2000,
+		CompressionMaxRSSMB:    100,
+		Verified:               true,
+		Iteration:              2,
+		CompressionUserS:       5.25,
+		CompressionMinorFaults: 42,
+		CacheState:             CacheStateCold,
+		Params:                 "long=27,strat=9",
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(csvHeader()); err != nil {
+		t.Fatalf("Failed to write header: %v", err)
+	}
+	if err := w.Write(csvRow(want)); err != nil {
+		t.Fatalf("Failed to write row: %v", err)
+	}
+	w.Flush()
+
+	got, err := ReadResultsCSV(&buf)
+	if err != nil {
+		t.Fatalf("ReadResultsCSV failed: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(got))
+	}
+	if got[0] != want {
+		t.Errorf("Round trip mismatch:\n got  %+v\n want %+v", got[0], want)
+	}
+}
+
+func TestReadResultsCSVOlderSchema(t *testing.T) {
+	data := "run_id,algorithm,level,file_path,compression_ratio,verified\n" +
+		"r1,xz,6,a.bin,0.2500,true\n"
+
+	got, err := ReadResultsCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadResultsCSV failed: %v", err)
+	}
+	if len(got) != 1 || got[0].Algorithm != "xz" || got[0].Level != 6 || got[0].CompressionRatio != 0.25 {
+		t.Errorf("Unexpected results: %+v", got)
+	}
+}
+
+func TestReadResultsCSVInvalidValue(t *testing.T) {
+	data := "run_id,level\nr1,high\n"
+	if _, err := ReadResultsCSV(strings.NewReader(data)); err == nil {
+		t.Error("Expected error for non-numeric level")
+	}
+}
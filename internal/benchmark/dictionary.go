@@ -0,0 +1,175 @@
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aomarai/compstat/internal/codec"
+	"github.com/aomarai/compstat/internal/corpus"
+	"github.com/aomarai/compstat/internal/util"
+)
+
+// DefaultDictionarySize matches the default maximum size of zstd --train
+const DefaultDictionarySize = 112640
+
+// dictionary is a dictionary file shared by the jobs of one codec and size
+type dictionary struct {
+	path   string
+	bytes  int64
+	trainS float64
+}
+
+// dictPath returns the dictionary's file, or "" for runs without one
+func (d *dictionary) dictPath() string {
+	if d == nil {
+		return ""
+	}
+	return d.path
+}
+
+// dictBytes returns the dictionary's size, or 0 for runs without one
+func (d *dictionary) dictBytes() int64 {
+	if d == nil {
+		return 0
+	}
+	return d.bytes
+}
+
+// trainSeconds returns how long the dictionary took to train, or 0 for runs
+// without one
+func (d *dictionary) trainSeconds() float64 {
+	if d == nil {
+		return 0
+	}
+	return d.trainS
+}
+
+// prepareDictionaries trains a dictionary of each configured size for every
+// codec that supports them. Each codec maps to the dictionaries it runs
+// with, where nil means no dictionary; codecs without dictionary support run
+// without one.
+func (r *Runner) prepareDictionaries(ctx context.Context, codecs []codec.Codec) (map[string][]*dictionary, error) {
+	dicts := make(map[string][]*dictionary, len(codecs))
+	for _, c := range codecs {
+		dicts[c.Name()] = []*dictionary{nil}
+	}
+	if len(r.config.DictSamples) == 0 {
+		return dicts, nil
+	}
+
+	samples, err := corpus.ExpandPaths(r.config.DictSamples)
+	if err != nil {
+		return nil, fmt.Errorf("dictionary samples: %w", err)
+	}
+	dir := filepath.Join(r.config.TmpDir, "dict")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create dictionary directory: %w", err)
+	}
+	sampleList := filepath.Join(dir, "samples.list")
+	if err := os.WriteFile(sampleList, []byte(strings.Join(samples, "\n")+"\n"), 0644); err != nil {
+		return nil, err
+	}
+
+	sizes := r.config.DictSizes
+	if len(sizes) == 0 {
+		sizes = []int64{DefaultDictionarySize}
+	}
+
+	fmt.Printf("\n=== Training dictionaries from %d sample(s) ===\n", len(samples))
+	for _, c := range codecs {
+		if !codec.SupportsDictionary(c) {
+			fmt.Printf("  %s does not support dictionaries, running without\n", c.Name())
+			continue
+		}
+		dicts[c.Name()] = nil
+		for _, size := range sizes {
+			if size == 0 {
+				dicts[c.Name()] = append(dicts[c.Name()], nil)
+				continue
+			}
+			path := filepath.Join(dir, fmt.Sprintf("%s-%d.dict", c.Name(), size))
+			d, err := trainDictionary(ctx, c, samples, sampleList, size, path)
+			if err != nil {
+				return nil, fmt.Errorf("training %s dictionary of %s: %w", c.Name(), util.FormatSize(size), err)
+			}
+			fmt.Printf("  %s: %s dictionary in %.3fs\n", c.Name(), util.FormatSize(d.bytes), d.trainS)
+			dicts[c.Name()] = append(dicts[c.Name()], d)
+		}
+	}
+	return dicts, nil
+}
+
+// removeDictionaries deletes the files written by prepareDictionaries
+func (r *Runner) removeDictionaries() {
+	if err := os.RemoveAll(filepath.Join(r.config.TmpDir, "dict")); err != nil {
+		fmt.Printf("warning: failed to remove dictionaries: %v\n", err)
+	}
+}
+
+// trainDictionary writes a dictionary of at most size bytes for c to path,
+// either by running the codec's trainer or, for codecs that take raw
+// dictionaries, by concatenating samples
+func trainDictionary(ctx context.Context, c codec.Codec, samples []string, sampleList string, size int64, path string) (*dictionary, error) {
+	var elapsed time.Duration
+	if args := c.(codec.DictionaryCodec).TrainDictionaryArgs(sampleList, size, path); args != nil {
+		m, err := util.RunCommand(ctx, c.Binary(), args, "", "")
+		if err != nil {
+			return nil, err
+		}
+		elapsed = m.Wall
+	} else {
+		start := time.Now()
+		if err := writeRawDictionary(samples, size, path); err != nil {
+			return nil, err
+		}
+		elapsed = time.Since(start)
+	}
+
+	n, err := util.FileSize(path)
+	if err != nil {
+		return nil, err
+	}
+	return &dictionary{path: path, bytes: n, trainS: elapsed.Seconds()}, nil
+}
+
+// writeRawDictionary concatenates samples into path, stopping at size bytes
+func writeRawDictionary(samples []string, size int64, path string) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	remaining := size
+	for _, sample := range samples {
+		if remaining == 0 {
+			break
+		}
+		n, err := copyPrefix(out, sample, remaining)
+		if err != nil {
+			_ = out.Close()
+			return err
+		}
+		remaining -= n
+	}
+	return out.Close()
+}
+
+// copyPrefix copies at most limit bytes of the file at path to w
+func copyPrefix(w io.Writer, path string, limit int64) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer func(f *os.File) {
+		err := f.Close()
+		if err != nil {
+			fmt.Printf("warning: failed to close file: %v\n", err)
+		}
+	}(f)
+
+	return io.Copy(w, io.LimitReader(f, limit))
+}
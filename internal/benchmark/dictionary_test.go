@@ -0,0 +1,67 @@
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aomarai/compstat/internal/codec"
+)
+
+func TestWriteRawDictionary(t *testing.T) {
+	dir := t.TempDir()
+	samples := make([]string, 0)
+	for _, s := range []string{"a", "b", "c"} {
+		path := filepath.Join(dir, s+".txt")
+		if err := os.WriteFile(path, []byte(strings.Repeat(s, 6)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		samples = append(samples, path)
+	}
+
+	path := filepath.Join(dir, "raw.dict")
+	if err := writeRawDictionary(samples, 10, path); err != nil {
+		t.Fatalf("writeRawDictionary failed: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "aaaaaabbbb" {
+		t.Errorf("Dictionary = %q, want the first 10 sample bytes", data)
+	}
+}
+
+func TestZstdDictionaryRun(t *testing.T) {
+	zstd := &codec.ZstdCodec{}
+	if !codec.SupportsDictionary(zstd) {
+		t.Skip("zstd with --train not available")
+	}
+
+	samples := t.TempDir()
+	for i := 0; i < 20; i++ {
+		line := fmt.Sprintf(`{"id":%d,"event":"login","status":"ok","region":"eu-west-1"}`, i)
+		if err := os.WriteFile(filepath.Join(samples, fmt.Sprintf("s%d.json", i)), []byte(line), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runner, input := newTestRunner(t, Config{DictSamples: []string{samples}, DictSizes: []int64{0, 4096}})
+	dicts, err := runner.prepareDictionaries(context.Background(), []codec.Codec{zstd})
+	if err != nil {
+		t.Fatalf("prepareDictionaries failed: %v", err)
+	}
+	defer runner.removeDictionaries()
+	if len(dicts["zstd"]) != 2 || dicts["zstd"][0] != nil || dicts["zstd"][1] == nil {
+		t.Fatalf("Expected no dictionary and one trained dictionary, got %v", dicts["zstd"])
+	}
+
+	d := dicts["zstd"][1]
+	result := runner.runSingleBenchmark(context.Background(), job{filePath: input, codec: zstd, level: 3, dict: d, iteration: 1})
+	if result == nil || !result.Verified {
+		t.Fatalf("Expected a verified result, got %+v", result)
+	}
+	if result.DictionaryBytes != d.bytes || result.DictionaryBytes == 0 {
+		t.Errorf("DictionaryBytes = %d, want %d", result.DictionaryBytes, d.bytes)
+	}
+}
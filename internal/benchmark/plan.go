@@ -0,0 +1,262 @@
+package benchmark
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aomarai/compstat/internal/codec"
+	"github.com/aomarai/compstat/internal/corpus"
+	"github.com/aomarai/compstat/internal/util"
+)
+
+// Plan is a declarative description of a benchmark run, loaded from a YAML,
+// TOML or JSON file. It maps onto Config.
+type Plan struct {
+	Inputs            []string        `json:"inputs" yaml:"inputs" toml:"inputs"`
+	Generate          []string        `json:"generate,omitempty" yaml:"generate" toml:"generate"`
+	InputMode         string          `json:"input_mode,omitempty" yaml:"input_mode" toml:"input_mode"`
+	Records           string          `json:"records,omitempty" yaml:"records" toml:"records"`
+	ChunkSizes        util.Spec       `json:"chunk_sizes,omitempty" yaml:"chunk_sizes" toml:"chunk_sizes"`
+	Codecs            []CodecPlan     `json:"codecs,omitempty" yaml:"codecs" toml:"codecs"`
+	Dictionary        *PlanDictionary `json:"dictionary,omitempty" yaml:"dictionary" toml:"dictionary"`
+	CompressThreads   util.Spec       `json:"compress_threads,omitempty" yaml:"compress_threads" toml:"compress_threads"`
+	DecompressThreads util.Spec       `json:"decompress_threads,omitempty" yaml:"decompress_threads" toml:"decompress_threads"`
+	Iterations        int             `json:"iterations,omitempty" yaml:"iterations" toml:"iterations"`
+	Warmup            int             `json:"warmup,omitempty" yaml:"warmup" toml:"warmup"`
+	Cache             string          `json:"cache,omitempty" yaml:"cache" toml:"cache"`
+	Stream            bool            `json:"stream,omitempty" yaml:"stream" toml:"stream"`
+	Parallelism       int             `json:"parallelism,omitempty" yaml:"parallelism" toml:"parallelism"`
+	Timeout           string          `json:"timeout,omitempty" yaml:"timeout" toml:"timeout"`
+	TmpDir            string          `json:"tmpdir,omitempty" yaml:"tmpdir" toml:"tmpdir"`
+	Verify            *bool           `json:"verify,omitempty" yaml:"verify" toml:"verify"`
+	SkipDecompression bool            `json:"skip_decompression,omitempty" yaml:"skip_decompression" toml:"skip_decompression"`
+	Resume            bool            `json:"resume,omitempty" yaml:"resume" toml:"resume"`
+	Output            PlanOutput      `json:"output" yaml:"output" toml:"output"`
+}
+
+// CodecPlan selects one codec and, optionally, its levels and parameter grid.
+// Levels use the same syntax as -levels; an empty list means the codec's
+// default sweep.
+type CodecPlan struct {
+	Name   string               `json:"name" yaml:"name" toml:"name"`
+	Levels util.Spec            `json:"levels,omitempty" yaml:"levels" toml:"levels"`
+	Params map[string]util.Spec `json:"params,omitempty" yaml:"params" toml:"params"`
+}
+
+// PlanDictionary trains dictionaries from samples, with the same syntax as
+// inputs. Sizes use the same syntax as -dict-size.
+type PlanDictionary struct {
+	Samples []string  `json:"samples" yaml:"samples" toml:"samples"`
+	Sizes   util.Spec `json:"sizes,omitempty" yaml:"sizes" toml:"sizes"`
+}
+
+// PlanOutput lists the files results are written to
+type PlanOutput struct {
+	CSV         string `json:"csv,omitempty" yaml:"csv" toml:"csv"`
+	JSON        string `json:"json,omitempty" yaml:"json" toml:"json"`
+	SummaryCSV  string `json:"summary_csv,omitempty" yaml:"summary_csv" toml:"summary_csv"`
+	SummaryJSON string `json:"summary_json,omitempty" yaml:"summary_json" toml:"summary_json"`
+	Corpus      string `json:"corpus_summary,omitempty" yaml:"corpus_summary" toml:"corpus_summary"`
+}
+
+// LoadPlan reads a plan file, choosing the format from its extension: .yaml
+// or .yml, .toml, or .json
+func LoadPlan(path string) (*Plan, error) {
+	var plan Plan
+	if err := util.DecodeConfigFile(path, &plan); err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// Config resolves the plan into a benchmark configuration, applying the same
+// defaults as the run flags
+func (p *Plan) Config() (Config, error) {
+	if len(p.Inputs) == 0 && len(p.Generate) == 0 {
+		return Config{}, fmt.Errorf("plan has no inputs")
+	}
+
+	config := Config{
+		Files:               p.Inputs,
+		InputMode:           p.InputMode,
+		Codecs:              make([]string, 0, len(p.Codecs)),
+		Iterations:          p.Iterations,
+		TmpDir:              p.TmpDir,
+		OutputCSV:           p.Output.CSV,
+		OutputJSON:          p.Output.JSON,
+		SummaryCSV:          p.Output.SummaryCSV,
+		SummaryJSON:         p.Output.SummaryJSON,
+		CorpusSummary:       p.Output.Corpus,
+		VerifyDecompression: p.Verify == nil || *p.Verify,
+		SkipDecompression:   p.SkipDecompression,
+		Parallelism:         p.Parallelism,
+		Streaming:           p.Stream,
+		WarmupRuns:          p.Warmup,
+		CacheMode:           p.Cache,
+		Resume:              p.Resume,
+	}
+	if config.Iterations == 0 {
+		config.Iterations = 1
+	}
+	if config.Parallelism == 0 {
+		config.Parallelism = 1
+	}
+	if config.TmpDir == "" {
+		config.TmpDir = filepath.Join(os.TempDir(), "compstat_tmp")
+	}
+	if config.OutputCSV == "" {
+		config.OutputCSV = "compstat_results.csv"
+	}
+	if config.CacheMode == "" {
+		config.CacheMode = CacheModeNone
+	}
+	if config.InputMode == "" {
+		config.InputMode = InputModeEach
+	}
+
+	for _, spec := range p.Generate {
+		s, err := corpus.ParseSpec(spec)
+		if err != nil {
+			return Config{}, err
+		}
+		config.Generate = append(config.Generate, s)
+	}
+
+	var err error
+	if config.Records, err = ParseRecordSplit(p.Records); err != nil {
+		return Config{}, err
+	}
+	if config.ChunkSizes, err = util.ParseSizes(string(p.ChunkSizes)); err != nil {
+		return Config{}, fmt.Errorf("chunk_sizes: %w", err)
+	}
+	if p.Dictionary != nil {
+		config.DictSamples = p.Dictionary.Samples
+		if config.DictSizes, err = util.ParseSizes(string(p.Dictionary.Sizes)); err != nil {
+			return Config{}, fmt.Errorf("dictionary sizes: %w", err)
+		}
+	}
+	if config.CompressThreads, err = util.ParseThreads(string(p.CompressThreads)); err != nil {
+		return Config{}, fmt.Errorf("compress_threads: %w", err)
+	}
+	if config.DecompressThreads, err = util.ParseThreads(string(p.DecompressThreads)); err != nil {
+		return Config{}, fmt.Errorf("decompress_threads: %w", err)
+	}
+	if p.Timeout != "" {
+		if config.Timeout, err = time.ParseDuration(p.Timeout); err != nil {
+			return Config{}, fmt.Errorf("timeout: %w", err)
+		}
+	}
+
+	if len(p.Codecs) == 0 {
+		config.Codecs = DefaultCodecs(config.Records)
+	}
+	for _, cp := range p.Codecs {
+		c, ok := codec.Registry[cp.Name]
+		if !ok {
+			return Config{}, fmt.Errorf("unknown codec %q", cp.Name)
+		}
+		config.Codecs = append(config.Codecs, cp.Name)
+		if cp.Levels != "" {
+			levels, err := codec.ParseLevels(c, string(cp.Levels))
+			if err != nil {
+				return Config{}, err
+			}
+			if config.Levels == nil {
+				config.Levels = make(map[string][]codec.Level)
+			}
+			config.Levels[cp.Name] = levels
+		}
+		for _, name := range sortedKeys(cp.Params) {
+			if config.Params == nil {
+				config.Params = make(map[string]codec.ParamGrid)
+			}
+			spec := fmt.Sprintf("%s:%s=%s", cp.Name, name, cp.Params[name])
+			if err := codec.ParseParamSpec(config.Params, spec); err != nil {
+				return Config{}, err
+			}
+		}
+	}
+	return config, nil
+}
+
+// PlanFromConfig describes config as a fully resolved plan, listing every
+// codec with its explicit levels and parameter grid, so it can be loaded
+// again to reproduce the run. Resume is left out: it says how the results
+// file was written, and a replayed plan would otherwise skip runs silently.
+func PlanFromConfig(config Config, codecs []codec.Codec) Plan {
+	verify := config.VerifyDecompression
+	plan := Plan{
+		Inputs:            config.Files,
+		InputMode:         config.InputMode,
+		Records:           config.Records.String(),
+		ChunkSizes:        util.Spec(util.FormatSizes(config.ChunkSizes)),
+		Codecs:            make([]CodecPlan, 0, len(codecs)),
+		CompressThreads:   intsSpec(config.CompressThreads),
+		DecompressThreads: intsSpec(config.DecompressThreads),
+		Iterations:        config.Iterations,
+		Warmup:            config.WarmupRuns,
+		Cache:             config.CacheMode,
+		Stream:            config.Streaming,
+		Parallelism:       config.Parallelism,
+		TmpDir:            config.TmpDir,
+		Verify:            &verify,
+		SkipDecompression: config.SkipDecompression,
+		Output: PlanOutput{
+			CSV:         config.OutputCSV,
+			JSON:        config.OutputJSON,
+			SummaryCSV:  config.SummaryCSV,
+			SummaryJSON: config.SummaryJSON,
+			Corpus:      config.CorpusSummary,
+		},
+	}
+	for _, spec := range config.Generate {
+		plan.Generate = append(plan.Generate, spec.String())
+	}
+	if config.Timeout > 0 {
+		plan.Timeout = config.Timeout.String()
+	}
+	if len(config.DictSamples) > 0 {
+		plan.Dictionary = &PlanDictionary{
+			Samples: config.DictSamples,
+			Sizes:   util.Spec(util.FormatSizes(config.DictSizes)),
+		}
+	}
+
+	for _, c := range codecs {
+		levels, ok := config.Levels[c.Name()]
+		if !ok {
+			levels = codec.PlainLevels(c.Levels())
+		}
+		cp := CodecPlan{Name: c.Name(), Levels: util.Spec(codec.FormatLevels(c, levels))}
+		if grid := config.Params[c.Name()]; len(grid) > 0 {
+			cp.Params = make(map[string]util.Spec, len(grid))
+			for name, values := range grid {
+				cp.Params[name] = util.Spec(strings.Join(values, ","))
+			}
+		}
+		plan.Codecs = append(plan.Codecs, cp)
+	}
+	return plan
+}
+
+func intsSpec(values []int) util.Spec {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return util.Spec(strings.Join(parts, ","))
+}
+
+func sortedKeys(m map[string]util.Spec) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,246 @@
+package benchmark
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aomarai/compstat/internal/codec"
+)
+
+const yamlPlan = `
+inputs: [a.bin, b.bin]
+generate: [json:1GiB, random:256MiB@7]
+records: fixed:4KiB
+codecs:
+  - name: zstd
+    levels: -1,3..5
+    params:
+      long: [-, 27]
+  - name: xz
+    levels: 9e
+  - name: lz4
+dictionary:
+  samples: [samples/]
+  sizes: [0, 16KiB]
+compress_threads: [1, 4]
+decompress_threads: 2
+iterations: 3
+timeout: 10m
+verify: false
+output:
+  csv: out.csv
+  json: out.json
+`
+
+const tomlPlan = `
+inputs = ["a.bin", "b.bin"]
+compress_threads = [1, 4]
+decompress_threads = 2
+iterations = 3
+timeout = "10m"
+verify = false
+
+[[codecs]]
+name = "zstd"
+levels = "-1,3..5"
+params = { long = ["-", 27] }
+
+[[codecs]]
+name = "xz"
+levels = "9e"
+
+[[codecs]]
+name = "lz4"
+
+[output]
+csv = "out.csv"
+json = "out.json"
+`
+
+const jsonPlan = `{
+  "inputs": ["a.bin", "b.bin"],
+  "codecs": [
+    {"name": "zstd", "levels": [-1, "3..5"], "params": {"long": ["-", 27]}},
+    {"name": "xz", "levels": "9e"},
+    {"name": "lz4"}
+  ],
+  "compress_threads": [1, 4],
+  "decompress_threads": 2,
+  "iterations": 3,
+  "timeout": "10m",
+  "verify": false,
+  "output": {"csv": "out.csv", "json": "out.json"}
+}`
+
+func writePlan(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write plan: %v", err)
+	}
+	return path
+}
+
+func TestLoadPlanFormats(t *testing.T) {
+	for name, content := range map[string]string{
+		"plan.yaml": yamlPlan,
+		"plan.toml": tomlPlan,
+		"plan.json": jsonPlan,
+	} {
+		plan, err := LoadPlan(writePlan(t, name, content))
+		if err != nil {
+			t.Fatalf("%s: LoadPlan failed: %v", name, err)
+		}
+		config, err := plan.Config()
+		if err != nil {
+			t.Fatalf("%s: Config failed: %v", name, err)
+		}
+
+		if !reflect.DeepEqual(config.Files, []string{"a.bin", "b.bin"}) {
+			t.Errorf("%s: unexpected files %v", name, config.Files)
+		}
+		if !reflect.DeepEqual(config.Codecs, []string{"zstd", "xz", "lz4"}) {
+			t.Errorf("%s: unexpected codecs %v", name, config.Codecs)
+		}
+		wantLevels := map[string][]codec.Level{
+			"zstd": codec.PlainLevels([]int{-1, 3, 4, 5}),
+			"xz":   {{N: 9, Params: codec.Params{"extreme": "true"}}},
+		}
+		if !reflect.DeepEqual(config.Levels, wantLevels) {
+			t.Errorf("%s: unexpected levels %v", name, config.Levels)
+		}
+		if got := config.Params["zstd"]["long"]; !reflect.DeepEqual(got, []string{"-", "27"}) {
+			t.Errorf("%s: unexpected zstd long values %v", name, got)
+		}
+		if !reflect.DeepEqual(config.CompressThreads, []int{1, 4}) || !reflect.DeepEqual(config.DecompressThreads, []int{2}) {
+			t.Errorf("%s: unexpected threads %v/%v", name, config.CompressThreads, config.DecompressThreads)
+		}
+		if config.Iterations != 3 || config.Timeout != 10*time.Minute || config.VerifyDecompression {
+			t.Errorf("%s: unexpected settings %+v", name, config)
+		}
+		if config.OutputCSV != "out.csv" || config.OutputJSON != "out.json" || config.Parallelism != 1 || config.CacheMode != CacheModeNone {
+			t.Errorf("%s: unexpected outputs or defaults %+v", name, config)
+		}
+	}
+}
+
+func TestLoadPlanErrors(t *testing.T) {
+	tests := map[string]string{
+		"unknown.yaml":  "inputs: [a.bin]\nbogus: 1\n",
+		"codec.yaml":    "inputs: [a.bin]\ncodecs: [{name: nosuch}]\n",
+		"level.yaml":    "inputs: [a.bin]\ncodecs: [{name: zstd, levels: 23}]\n",
+		"param.yaml":    "inputs: [a.bin]\ncodecs: [{name: zstd, params: {nosuch: 1}}]\n",
+		"noinputs.json": `{"iterations": 2}`,
+		"generate.yaml": "generate: [nosuch:1MiB]\n",
+		"records.yaml":  "inputs: [a.bin]\nrecords: csv\n",
+		"dict.yaml":     "inputs: [a.bin]\ndictionary: {samples: [s], sizes: big}\n",
+		"plan.ini":      "inputs=a.bin",
+	}
+	for name, content := range tests {
+		plan, err := LoadPlan(writePlan(t, name, content))
+		if err == nil {
+			_, err = plan.Config()
+		}
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestPlanFromConfigRoundTrip(t *testing.T) {
+	plan, err := LoadPlan(writePlan(t, "plan.yaml", yamlPlan))
+	if err != nil {
+		t.Fatalf("LoadPlan failed: %v", err)
+	}
+	config, err := plan.Config()
+	if err != nil {
+		t.Fatalf("Config failed: %v", err)
+	}
+
+	if config.Records != (RecordSplit{Kind: RecordsFixed, Size: 4096}) {
+		t.Errorf("Unexpected record format %+v", config.Records)
+	}
+	if !reflect.DeepEqual(config.DictSizes, []int64{0, 16 << 10}) {
+		t.Errorf("Unexpected dictionary sizes %v", config.DictSizes)
+	}
+
+	codecs := []codec.Codec{codec.Registry["zstd"], codec.Registry["xz"], codec.Registry["lz4"]}
+	data, err := json.Marshal(PlanFromConfig(config, codecs))
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	reloaded, err := LoadPlan(writePlan(t, "resolved.json", string(data)))
+	if err != nil {
+		t.Fatalf("Resolved plan does not load: %v", err)
+	}
+	again, err := reloaded.Config()
+	if err != nil {
+		t.Fatalf("Resolved plan does not resolve: %v", err)
+	}
+
+	config.Levels["lz4"] = codec.PlainLevels(codec.Registry["lz4"].Levels())
+	if !reflect.DeepEqual(again, config) {
+		t.Errorf("Round trip mismatch:\n got  %+v\n want %+v", again, config)
+	}
+}
+
+func TestPlanFromConfigOmitsResume(t *testing.T) {
+	plan, err := LoadPlan(writePlan(t, "plan.yaml", "inputs: [a.bin]\ncodecs: [{name: lz4}]\nresume: true\n"))
+	if err != nil {
+		t.Fatalf("LoadPlan failed: %v", err)
+	}
+	config, err := plan.Config()
+	if err != nil {
+		t.Fatalf("Config failed: %v", err)
+	}
+	if !config.Resume {
+		t.Fatal("Expected the plan file to turn on resume")
+	}
+
+	data, err := json.Marshal(PlanFromConfig(config, []codec.Codec{codec.Registry["lz4"]}))
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if strings.Contains(string(data), "resume") {
+		t.Errorf("Resolved plan records resume: %s", data)
+	}
+	reloaded, err := LoadPlan(writePlan(t, "resolved.json", string(data)))
+	if err != nil {
+		t.Fatalf("Resolved plan does not load: %v", err)
+	}
+	again, err := reloaded.Config()
+	if err != nil {
+		t.Fatalf("Resolved plan does not resolve: %v", err)
+	}
+	if again.Resume {
+		t.Error("Replaying the resolved plan turned on resume")
+	}
+}
+
+func TestLoadResultsJSONLayouts(t *testing.T) {
+	results := []Result{{Algorithm: "zstd", Level: 3, Status: StatusOK}}
+
+	bare, err := json.Marshal(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrapped, err := json.Marshal(ResultsFile{Plan: Plan{Inputs: []string{"a.bin"}}, Results: results})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, data := range map[string][]byte{"bare.json": bare, "wrapped.json": wrapped} {
+		got, err := LoadResults(writePlan(t, name, string(data)))
+		if err != nil {
+			t.Fatalf("%s: LoadResults failed: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, results) {
+			t.Errorf("%s: got %+v, want %+v", name, got, results)
+		}
+	}
+}
@@ -0,0 +1,368 @@
+package benchmark
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aomarai/compstat/internal/codec"
+	"github.com/aomarai/compstat/internal/util"
+)
+
+// Record formats for RecordSplit.Kind
+const (
+	RecordsLines          = "lines"
+	RecordsFixed          = "fixed"
+	RecordsLengthPrefixed = "length-prefixed"
+)
+
+// RecordSplit describes how record mode splits an input into records that
+// are compressed independently. The zero value benchmarks whole files.
+type RecordSplit struct {
+	Kind string
+	// Size is the record size of fixed records
+	Size int64
+}
+
+// ParseRecordSplit parses a record format: lines (newline-delimited),
+// fixed:SIZE, or length-prefixed (each record preceded by its length as a
+// 4-byte big-endian integer). An empty spec disables record mode.
+func ParseRecordSplit(spec string) (RecordSplit, error) {
+	spec = strings.TrimSpace(spec)
+	kind, size, hasSize := strings.Cut(spec, ":")
+	switch kind {
+	case "":
+		return RecordSplit{}, nil
+	case RecordsLines, RecordsLengthPrefixed:
+		if hasSize {
+			return RecordSplit{}, fmt.Errorf("record format %q takes no size", kind)
+		}
+		return RecordSplit{Kind: kind}, nil
+	case RecordsFixed:
+		n, err := util.ParseSize(size)
+		if err != nil || n == 0 {
+			return RecordSplit{}, fmt.Errorf("record format %q needs a positive size, e.g. fixed:4KiB", spec)
+		}
+		return RecordSplit{Kind: kind, Size: n}, nil
+	}
+	return RecordSplit{}, fmt.Errorf("unknown record format %q (want %s, %s:SIZE or %s)", spec, RecordsLines, RecordsFixed, RecordsLengthPrefixed)
+}
+
+// String returns the form ParseRecordSplit accepts, which results record in
+// record_format
+func (s RecordSplit) String() string {
+	if s.Kind == RecordsFixed {
+		return s.Kind + ":" + util.FormatSize(s.Size)
+	}
+	return s.Kind
+}
+
+// DefaultCodecs returns the registered codec names to benchmark when none are
+// selected, in name order: every codec, or only the in-process ones in record
+// mode
+func DefaultCodecs(records RecordSplit) []string {
+	names := make([]string, 0, len(codec.Registry))
+	for name, c := range codec.Registry {
+		if _, inProcess := c.(codec.InProcessCodec); records.Kind == "" || inProcess {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkRecordCodecs rejects exec codecs in record mode. Each record would
+// start a process, so its latency would mostly measure process start-up.
+func checkRecordCodecs(names []string) error {
+	external := make([]string, 0)
+	for _, name := range names {
+		c, ok := codec.Registry[name]
+		if _, inProcess := c.(codec.InProcessCodec); ok && !inProcess {
+			external = append(external, name)
+		}
+	}
+	if len(external) == 0 {
+		return nil
+	}
+	return fmt.Errorf("record mode needs in-process codecs: %s would start a process per record, "+
+		"so record latencies would measure process start-up; use the go-* codecs instead", strings.Join(external, ", "))
+}
+
+// Split divides data into records. Lines keep no trailing newline, and empty
+// lines are skipped.
+func (s RecordSplit) Split(data []byte) ([][]byte, error) {
+	records := make([][]byte, 0)
+	switch s.Kind {
+	case RecordsLines:
+		for _, line := range bytes.Split(data, []byte("\n")) {
+			if len(line) > 0 {
+				records = append(records, line)
+			}
+		}
+	case RecordsFixed:
+		for len(data) > 0 {
+			n := min(int64(len(data)), s.Size)
+			records = append(records, data[:n])
+			data = data[n:]
+		}
+	case RecordsLengthPrefixed:
+		for len(data) > 0 {
+			if len(data) < 4 {
+				return nil, fmt.Errorf("truncated length prefix after %d record(s)", len(records))
+			}
+			n := binary.BigEndian.Uint32(data)
+			data = data[4:]
+			if uint64(n) > uint64(len(data)) {
+				return nil, fmt.Errorf("record %d claims %d bytes, only %d left", len(records)+1, n, len(data))
+			}
+			records = append(records, data[:n])
+			data = data[n:]
+		}
+	default:
+		return nil, fmt.Errorf("unknown record format %q", s.Kind)
+	}
+	return records, nil
+}
+
+// recordPass is the outcome of compressing or decompressing every record
+type recordPass struct {
+	out       [][]byte
+	latencies []float64 // per record, in microseconds
+	measure   util.Measurement
+}
+
+// streamRecords codes each record with a full streaming Compress or
+// Decompress call, for codecs that cannot reuse state between records
+type streamRecords func(dst io.Writer, src io.Reader) error
+
+func (s streamRecords) Append(dst, src []byte) ([]byte, error) {
+	buf := bytes.NewBuffer(dst)
+	err := s(buf, bytes.NewReader(src))
+	return buf.Bytes(), err
+}
+
+func (s streamRecords) Close() error {
+	return nil
+}
+
+// recordCoders creates the coders of one compression or decompression pass
+type recordCoders struct {
+	compressor   func() (codec.RecordCoder, error)
+	decompressor func() (codec.RecordCoder, error)
+}
+
+// inProcessCoders codes records inside the compstat process, reusing the
+// codec's encoder and decoder when it can
+func inProcessCoders(c codec.InProcessCodec, level, compThreads, decompThreads int) recordCoders {
+	rc, reusable := c.(codec.RecordCodec)
+	return recordCoders{
+		compressor: func() (codec.RecordCoder, error) {
+			if reusable {
+				return rc.NewRecordCompressor(level, compThreads)
+			}
+			return streamRecords(func(dst io.Writer, src io.Reader) error {
+				return c.Compress(dst, src, level, compThreads)
+			}), nil
+		},
+		decompressor: func() (codec.RecordCoder, error) {
+			if reusable {
+				return rc.NewRecordDecompressor(decompThreads)
+			}
+			return streamRecords(func(dst io.Writer, src io.Reader) error {
+				return c.Decompress(dst, src, decompThreads)
+			}), nil
+		},
+	}
+}
+
+// runRecords passes every record through a coder from newCoder, timing each
+// one. Setting up the coder is not timed. Outputs are handed to sink, or
+// kept in the pass when sink is nil.
+func runRecords(ctx context.Context, newCoder func() (codec.RecordCoder, error), records [][]byte, sink func(i int, out []byte)) (recordPass, error) {
+	coder, err := newCoder()
+	if err != nil {
+		return recordPass{}, err
+	}
+	defer func() {
+		if err := coder.Close(); err != nil {
+			fmt.Printf("warning: failed to close record coder: %v\n", err)
+		}
+	}()
+
+	pass := recordPass{latencies: make([]float64, len(records))}
+	if sink == nil {
+		pass.out = make([][]byte, len(records))
+		sink = func(i int, out []byte) { pass.out[i] = out }
+	}
+	var elapsed time.Duration
+	m, err := util.MeasureFunc(func() error {
+		for i, record := range records {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			start := time.Now()
+			out, err := coder.Append(nil, record)
+			latency := time.Since(start)
+			if err != nil {
+				return fmt.Errorf("record %d: %w", i+1, err)
+			}
+			elapsed += latency
+			pass.latencies[i] = float64(latency.Nanoseconds()) / 1e3
+			sink(i, out)
+		}
+		return nil
+	})
+	// Only time spent coding counts, not handing outputs to the sink
+	m.Wall = elapsed
+	pass.measure = m
+	return pass, err
+}
+
+// percentiles returns the p50 and p99 per-record latency of the pass
+func (p recordPass) percentiles() (p50, p99 float64) {
+	if len(p.latencies) == 0 {
+		return 0, 0
+	}
+	sorted := append([]float64(nil), p.latencies...)
+	sort.Float64s(sorted)
+	return percentile(sorted, 50), percentile(sorted, 99)
+}
+
+// recordsPerSecond returns the record throughput of the pass
+func (p recordPass) recordsPerSecond() float64 {
+	if p.measure.Wall <= 0 {
+		return 0
+	}
+	return float64(len(p.latencies)) / p.measure.Wall.Seconds()
+}
+
+// runRecordBenchmark benchmarks j in record or chunk mode: the input is
+// split into records in memory and each record is compressed and
+// decompressed on its own. In-process codecs code records inside the
+// compstat process, so no process start-up is timed; exec codecs, which only
+// chunk mode runs, start a process per chunk, so their per-chunk latencies
+// include process start-up.
+func (r *Runner) runRecordBenchmark(ctx, runCtx context.Context, j job, result *Result, compOut, decompOut string) *Result {
+	c := j.codec.(codec.Codec)
+	coders := execCoders(runCtx, c, j, compOut, decompOut)
+	ip, inProcess := c.(codec.InProcessCodec)
+	if inProcess {
+		coders = inProcessCoders(ip, j.level, j.compressThreads, j.decompressThreads)
+	}
+	result.RecordFormat = j.records.String()
+	result.CacheState = r.prepareCache(j.filePath)
+
+	data, err := os.ReadFile(j.filePath)
+	if err != nil {
+		return r.recordFailure(ctx, runCtx, result, "reading input", err)
+	}
+	records, err := j.records.Split(data)
+	if err != nil {
+		return r.recordFailure(ctx, runCtx, result, "splitting records", err)
+	}
+	if len(records) == 0 {
+		return r.recordFailure(ctx, runCtx, result, "splitting records", fmt.Errorf("input has no records"))
+	}
+	var recordBytes int64
+	for _, record := range records {
+		recordBytes += int64(len(record))
+	}
+	result.Records = int64(len(records))
+	result.UncompressedBytes = recordBytes
+
+	// Untimed warm-up passes
+	for i := 0; i < r.config.WarmupRuns; i++ {
+		warm, err := runRecords(runCtx, coders.compressor, records, nil)
+		if err == nil && !r.config.SkipDecompression {
+			_, err = runRecords(runCtx, coders.decompressor, warm.out, func(int, []byte) {})
+		}
+		if err != nil {
+			if runCtx.Err() != nil {
+				return r.recordFailure(ctx, runCtx, result, "warm-up", err)
+			}
+			fmt.Printf("  warning: warm-up run failed: %v\n", err)
+			break
+		}
+	}
+
+	comp, err := runRecords(runCtx, coders.compressor, records, nil)
+	if err != nil {
+		return r.recordFailure(ctx, runCtx, result, "compression", err)
+	}
+	if !inProcess {
+		// The compstat process's own usage says nothing about the codec's
+		comp.measure = util.Measurement{Wall: comp.measure.Wall}
+	}
+	var compSize int64
+	for _, out := range comp.out {
+		compSize += int64(len(out))
+	}
+	result.CompressedBytes = compSize
+	result.CompressionRatio = float64(compSize) / float64(recordBytes)
+	result.CompressionTimeS = comp.measure.Wall.Seconds()
+	result.CompressionSpeedMBs = float64(recordBytes) / (1024 * 1024) / result.CompressionTimeS
+	result.CompressionMaxRSSMB = bytesToMB(comp.measure.MaxRSSBytes)
+	result.CompressionUserS = comp.measure.User.Seconds()
+	result.CompressionSysS = comp.measure.System.Seconds()
+	result.CompressionRecordP50Us, result.CompressionRecordP99Us = comp.percentiles()
+	result.CompressionRecordsPerS = comp.recordsPerSecond()
+
+	if r.config.SkipDecompression {
+		return result
+	}
+
+	// Fixed-size chunks are reassembled and checked against the input hash;
+	// other records lose their framing, so each is compared on its own
+	reassembled := util.NewHashingWriter()
+	mismatch := 0
+	decomp, err := runRecords(runCtx, coders.decompressor, comp.out, func(i int, out []byte) {
+		if j.records.Kind == RecordsFixed {
+			_, _ = reassembled.Write(out)
+		} else if mismatch == 0 && !bytes.Equal(out, records[i]) {
+			mismatch = i + 1
+		}
+	})
+	if err != nil {
+		return r.recordFailure(ctx, runCtx, result, "decompression", err)
+	}
+	if !inProcess {
+		decomp.measure = util.Measurement{Wall: decomp.measure.Wall}
+	}
+	result.DecompressionTimeS = decomp.measure.Wall.Seconds()
+	result.DecompressionSpeedMBs = float64(recordBytes) / (1024 * 1024) / result.DecompressionTimeS
+	result.DecompressionMaxRSSMB = bytesToMB(decomp.measure.MaxRSSBytes)
+	result.DecompressionUserS = decomp.measure.User.Seconds()
+	result.DecompressionSysS = decomp.measure.System.Seconds()
+	result.DecompressionRecordP50Us, result.DecompressionRecordP99Us = decomp.percentiles()
+	result.DecompressionRecordsPerS = decomp.recordsPerSecond()
+
+	if !r.config.VerifyDecompression {
+		return result
+	}
+	if j.records.Kind == RecordsFixed {
+		origHash, ok := r.fileHashes[j.filePath]
+		if !ok {
+			return result
+		}
+		if reassembled.Count() != int64(len(data)) || reassembled.Sum() != origHash {
+			result.Status = StatusVerifyFailed
+			result.Error = "verification failed: reassembled chunks do not match input"
+			fmt.Println("  ! Verification failed")
+			return result
+		}
+	} else if mismatch > 0 {
+		result.Status = StatusVerifyFailed
+		result.Error = fmt.Sprintf("verification failed: record %d does not match input", mismatch)
+		fmt.Println("  ! Verification failed")
+		return result
+	}
+	result.Verified = true
+	fmt.Println("  ✓ Verified")
+	return result
+}
@@ -0,0 +1,111 @@
+package benchmark
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aomarai/compstat/internal/codec"
+)
+
+func TestParseRecordSplit(t *testing.T) {
+	tests := map[string]RecordSplit{
+		"":                {},
+		"lines":           {Kind: RecordsLines},
+		"fixed:4KiB":      {Kind: RecordsFixed, Size: 4096},
+		"length-prefixed": {Kind: RecordsLengthPrefixed},
+	}
+	for spec, want := range tests {
+		got, err := ParseRecordSplit(spec)
+		if err != nil {
+			t.Errorf("ParseRecordSplit(%q) failed: %v", spec, err)
+			continue
+		}
+		if got != want || got.String() != spec {
+			t.Errorf("ParseRecordSplit(%q) = %+v (%q)", spec, got, got.String())
+		}
+	}
+
+	for _, spec := range []string{"fixed", "fixed:0", "lines:4", "csv"} {
+		if _, err := ParseRecordSplit(spec); err == nil {
+			t.Errorf("ParseRecordSplit(%q) succeeded, want error", spec)
+		}
+	}
+}
+
+func TestRecordSplit(t *testing.T) {
+	lines, _ := RecordSplit{Kind: RecordsLines}.Split([]byte("a\nbb\n\nccc\n"))
+	if len(lines) != 3 || string(lines[2]) != "ccc" {
+		t.Errorf("Lines split = %q", lines)
+	}
+
+	fixed, _ := RecordSplit{Kind: RecordsFixed, Size: 4}.Split([]byte("abcdefghij"))
+	if len(fixed) != 3 || string(fixed[2]) != "ij" {
+		t.Errorf("Fixed split = %q", fixed)
+	}
+
+	var framed []byte
+	for _, record := range []string{"first", "", "third"} {
+		framed = binary.BigEndian.AppendUint32(framed, uint32(len(record)))
+		framed = append(framed, record...)
+	}
+	prefixed, err := RecordSplit{Kind: RecordsLengthPrefixed}.Split(framed)
+	if err != nil || len(prefixed) != 3 || string(prefixed[2]) != "third" {
+		t.Errorf("Length-prefixed split = %q, %v", prefixed, err)
+	}
+	if _, err := (RecordSplit{Kind: RecordsLengthPrefixed}).Split(framed[:len(framed)-1]); err == nil {
+		t.Error("Expected an error for a truncated record")
+	}
+}
+
+func TestRecordBenchmark(t *testing.T) {
+	for _, c := range []codec.Codec{&fakeCodec{}, &codec.GoZstdCodec{}} {
+		t.Run(c.Name(), func(t *testing.T) {
+			runner, _ := newTestRunner(t, Config{})
+			input := filepath.Join(t.TempDir(), "events.jsonl")
+			data := []byte("{\"id\":1,\"event\":\"login\"}\n{\"id\":2,\"event\":\"logout\"}\n{\"id\":3,\"event\":\"login\"}\n")
+			if err := os.WriteFile(input, data, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			records := RecordSplit{Kind: RecordsLines}
+			j := job{filePath: input, codec: c, level: c.Levels()[0], records: records, iteration: 1, compressThreads: 1, decompressThreads: 1}
+			result := runner.runSingleBenchmark(context.Background(), j)
+			if result == nil || !result.Verified {
+				t.Fatalf("Expected a verified result, got %+v", result)
+			}
+			if result.Records != 3 || result.RecordFormat != "lines" || result.UncompressedBytes != int64(len(data)-3) {
+				t.Errorf("Unexpected record totals %d %q %d", result.Records, result.RecordFormat, result.UncompressedBytes)
+			}
+			if result.CompressionRecordP99Us < result.CompressionRecordP50Us || result.CompressionRecordsPerS <= 0 || result.DecompressionRecordsPerS <= 0 {
+				t.Errorf("Unexpected record latencies %+v", result)
+			}
+		})
+	}
+}
+
+func TestRecordModeRejectsExecCodecs(t *testing.T) {
+	dir := t.TempDir()
+	config := Config{
+		Records:   RecordSplit{Kind: RecordsLines},
+		Codecs:    []string{"go-zstd", "zstd"},
+		TmpDir:    filepath.Join(dir, "tmp"),
+		OutputCSV: filepath.Join(dir, "results.csv"),
+	}
+	_, err := NewRunner(config)
+	if err == nil || !strings.Contains(err.Error(), "zstd would start a process per record") {
+		t.Errorf("Expected record mode to reject zstd, got %v", err)
+	}
+
+	for _, name := range DefaultCodecs(config.Records) {
+		if _, ok := codec.Registry[name].(codec.InProcessCodec); !ok {
+			t.Errorf("Default record mode codecs include exec codec %s", name)
+		}
+	}
+	if len(DefaultCodecs(RecordSplit{})) != len(codec.Registry) {
+		t.Error("Expected every codec by default outside record mode")
+	}
+}
@@ -0,0 +1,97 @@
+package benchmark
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/aomarai/compstat/internal/codec"
+)
+
+// resultKey identifies one benchmark run for resuming
+type resultKey struct {
+	filePath          string
+	algorithm         string
+	level             int
+	params            string
+	dictionaryBytes   int64
+	recordFormat      string
+	iteration         int
+	compressThreads   int
+	decompressThreads int
+}
+
+func (j job) key() resultKey {
+	return resultKey{j.input(), j.codec.(codec.Codec).Name(), j.level, j.params.String(), j.dict.dictBytes(), j.records.String(), j.iteration, j.compressThreads, j.decompressThreads}
+}
+
+func keyOf(r Result) resultKey {
+	return resultKey{r.FilePath, r.Algorithm, r.Level, r.Params, r.DictionaryBytes, r.RecordFormat, r.Iteration, r.CompressThreads, r.DecompressThreads}
+}
+
+// loadExistingCSV reads the header and rows of an existing results file
+func loadExistingCSV(path string) ([]string, []Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func(f *os.File) {
+		err := f.Close()
+		if err != nil {
+			fmt.Printf("warning: failed to close file: %v\n", err)
+		}
+	}(f)
+
+	header, err := csv.NewReader(f).Read()
+	if err != nil && err != io.EOF {
+		return nil, nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, nil, err
+	}
+	results, err := ReadResultsCSV(f)
+	if err != nil {
+		return nil, nil, err
+	}
+	return header, results, nil
+}
+
+// prepareResume checks that the existing CSV matches the current schema and
+// records which runs it already completed. Failed and timed-out runs are not
+// completed, so they run again and their new rows are appended.
+func (r *Runner) prepareResume() error {
+	if err := dropPartialRow(r.config.OutputCSV); err != nil {
+		return fmt.Errorf("failed to truncate partial row: %w", err)
+	}
+	header, existing, err := loadExistingCSV(r.config.OutputCSV)
+	if err != nil {
+		return fmt.Errorf("failed to read existing CSV: %w", err)
+	}
+	if !slices.Equal(header, csvHeader()) {
+		return fmt.Errorf("existing CSV %s was written with a different schema; move it aside to start a new run", r.config.OutputCSV)
+	}
+
+	r.completed = make(map[resultKey]bool, len(existing))
+	for _, res := range existing {
+		if res.Succeeded() {
+			r.completed[keyOf(res)] = true
+		}
+	}
+	r.results = append(r.results, existing...)
+	return nil
+}
+
+// dropPartialRow truncates path after its last complete line, removing a
+// row left incomplete by an interrupted run
+func dropPartialRow(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) == 0 || data[len(data)-1] == '\n' {
+		return err
+	}
+	end := bytes.LastIndexByte(data, '\n') + 1
+	fmt.Printf("warning: dropping incomplete last row of %s\n", path)
+	return os.Truncate(path, int64(end))
+}
@@ -0,0 +1,126 @@
+package benchmark
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestCSV(t *testing.T, path string, header []string, results ...Result) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create CSV: %v", err)
+	}
+	w := csv.NewWriter(f)
+	if err := w.Write(header); err != nil {
+		t.Fatalf("Failed to write header: %v", err)
+	}
+	for _, res := range results {
+		if err := w.Write(csvRow(res)); err != nil {
+			t.Fatalf("Failed to write row: %v", err)
+		}
+	}
+	w.Flush()
+	if err := f.Close(); err != nil {
+		t.Fatalf("Failed to close CSV: %v", err)
+	}
+}
+
+func TestResumeLoadsCompletedRuns(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "results.csv")
+	done := Result{FilePath: "a.bin", Algorithm: "zstd", Level: 3, Iteration: 1, CompressThreads: 4, DecompressThreads: 4}
+	writeTestCSV(t, output, csvHeader(), done)
+
+	runner, err := NewRunner(Config{TmpDir: dir, OutputCSV: output, Resume: true})
+	if err != nil {
+		t.Fatalf("NewRunner failed: %v", err)
+	}
+	defer runner.Close()
+
+	if !runner.completed[keyOf(done)] {
+		t.Error("Expected existing run to be marked completed")
+	}
+	other := done
+	other.Iteration = 2
+	if runner.completed[keyOf(other)] {
+		t.Error("Expected other iteration not to be marked completed")
+	}
+	if runner.ResultCount() != 1 {
+		t.Errorf("Expected existing result to be loaded, got %d", runner.ResultCount())
+	}
+}
+
+func TestResumeRejectsSchemaMismatch(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "results.csv")
+	writeTestCSV(t, output, []string{"run_id", "algorithm", "level"})
+
+	_, err := NewRunner(Config{TmpDir: dir, OutputCSV: output, Resume: true})
+	if err == nil || !strings.Contains(err.Error(), "different schema") {
+		t.Errorf("Expected schema mismatch error, got %v", err)
+	}
+}
+
+func TestResumeDropsPartialRow(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "results.csv")
+	done := Result{FilePath: "a.bin", Algorithm: "zstd", Level: 3, Iteration: 1, Status: StatusOK}
+	writeTestCSV(t, output, csvHeader(), done)
+	complete, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	f, err := os.OpenFile(output, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open CSV: %v", err)
+	}
+	if _, err := f.WriteString("run-2,zstd,3,1,1,a.bin,10"); err != nil {
+		t.Fatalf("Failed to write partial row: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Failed to close CSV: %v", err)
+	}
+
+	runner, err := NewRunner(Config{TmpDir: dir, OutputCSV: output, Resume: true})
+	if err != nil {
+		t.Fatalf("NewRunner failed: %v", err)
+	}
+	if runner.ResultCount() != 1 {
+		t.Errorf("Expected only the complete row to be loaded, got %d results", runner.ResultCount())
+	}
+	runner.Close()
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	if string(data) != string(complete) {
+		t.Errorf("Expected the partial row to be truncated, got %q", data[len(complete)-1:])
+	}
+}
+
+func TestResumeRetriesFailedRuns(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "results.csv")
+	ok := Result{FilePath: "a.bin", Algorithm: "zstd", Level: 3, Iteration: 1, Status: StatusOK}
+	failed := Result{FilePath: "a.bin", Algorithm: "zstd", Level: 3, Iteration: 2, Status: StatusFailed}
+	timeout := Result{FilePath: "a.bin", Algorithm: "zstd", Level: 3, Iteration: 3, Status: StatusTimeout}
+	writeTestCSV(t, output, csvHeader(), ok, failed, timeout)
+
+	runner, err := NewRunner(Config{TmpDir: dir, OutputCSV: output, Resume: true})
+	if err != nil {
+		t.Fatalf("NewRunner failed: %v", err)
+	}
+	defer runner.Close()
+
+	if !runner.completed[keyOf(ok)] {
+		t.Error("Expected the successful run to be marked completed")
+	}
+	if runner.completed[keyOf(failed)] || runner.completed[keyOf(timeout)] {
+		t.Error("Expected failed and timed-out runs to be retried")
+	}
+}
@@ -1,16 +1,22 @@
 package benchmark
 
 import (
+	"bytes"
+	"context"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-	"strconv"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/aomarai/compstat/internal/codec"
+	"github.com/aomarai/compstat/internal/corpus"
 	"github.com/aomarai/compstat/internal/util"
 )
 
@@ -23,6 +29,12 @@ type Runner struct {
 	csvFile    *os.File
 	csvWriter  *csv.Writer
 	csvMux     sync.Mutex
+	completed  map[resultKey]bool
+	codecs     []codec.Codec
+	// inputs lists the files to benchmark: the configured files followed by
+	// generated ones, whose specs labels records in place of their paths
+	inputs []string
+	labels map[string]string
 }
 
 // NewRunner creates a new benchmark runner
@@ -31,13 +43,39 @@ func NewRunner(config Config) (*Runner, error) {
 		config:     config,
 		results:    make([]Result, 0),
 		fileHashes: make(map[string]string),
+		labels:     make(map[string]string),
 	}
 
+	inputs, err := corpus.ExpandPaths(config.Files)
+	if err != nil {
+		return nil, err
+	}
+	runner.inputs = inputs
+
 	// Create tmpdir
 	if err := os.MkdirAll(config.TmpDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create tmpdir: %w", err)
 	}
 
+	switch config.CacheMode {
+	case "", CacheModeNone, CacheModeDrop, CacheModeWarm:
+	default:
+		return nil, fmt.Errorf("unknown cache mode %q (want %s, %s or %s)", config.CacheMode, CacheModeNone, CacheModeDrop, CacheModeWarm)
+	}
+	switch config.InputMode {
+	case "", InputModeEach, InputModeConcat:
+	default:
+		return nil, fmt.Errorf("unknown input mode %q (want %s or %s)", config.InputMode, InputModeEach, InputModeConcat)
+	}
+	if config.Records.Kind != "" && len(config.ChunkSizes) > 0 {
+		return nil, fmt.Errorf("record mode and chunk sizes cannot be combined")
+	}
+	if config.Records.Kind != "" {
+		if err := checkRecordCodecs(config.Codecs); err != nil {
+			return nil, err
+		}
+	}
+
 	// Open CSV file for writing
 	csvFile, err := os.OpenFile(config.OutputCSV, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
@@ -50,20 +88,31 @@ func NewRunner(config Config) (*Runner, error) {
 	fileInfo, _ := csvFile.Stat()
 	if fileInfo.Size() == 0 {
 		runner.writeCSVHeader()
+	} else if config.Resume {
+		if err := runner.prepareResume(); err != nil {
+			runner.Close()
+			return nil, err
+		}
+	} else if header, _, err := loadExistingCSV(config.OutputCSV); err == nil && !slices.Equal(header, csvHeader()) {
+		fmt.Printf("warning: appending to %s, which has a different header\n", config.OutputCSV)
 	}
 
 	return runner, nil
 }
 
-// Close cleans up resources
+// Close flushes and closes the CSV output. It is safe to call more than once.
 func (r *Runner) Close() {
+	r.csvMux.Lock()
+	defer r.csvMux.Unlock()
 	if r.csvWriter != nil {
 		r.csvWriter.Flush()
+		r.csvWriter = nil
 	}
 	if r.csvFile != nil {
 		if err := r.csvFile.Close(); err != nil {
 			fmt.Printf("warning: failed to close CSV file: %v\n", err)
 		}
+		r.csvFile = nil
 	}
 }
 
@@ -75,13 +124,7 @@ func (r *Runner) ResultCount() int {
 }
 
 func (r *Runner) writeCSVHeader() {
-	header := []string{
-		"run_id", "algorithm", "level", "compress_threads", "decompress_threads",
-		"file_path", "uncompressed_bytes", "compressed_bytes", "compression_ratio",
-		"compression_time_s", "decompression_time_s", "compression_speed_mbs",
-		"decompression_speed_mbs", "compression_max_rss_mb", "decompression_max_rss_mb",
-		"verified", "iteration",
-	}
+	header := csvHeader()
 	if err := r.csvWriter.Write(header); err != nil {
 		fmt.Printf("warning: failed to write CSV header: %v\n", err)
 	}
@@ -92,25 +135,7 @@ func (r *Runner) writeResult(result Result) {
 	r.csvMux.Lock()
 	defer r.csvMux.Unlock()
 
-	row := []string{
-		result.RunID,
-		result.Algorithm,
-		strconv.Itoa(result.Level),
-		strconv.Itoa(result.CompressThreads),
-		strconv.Itoa(result.DecompressThreads),
-		result.FilePath,
-		strconv.FormatInt(result.UncompressedBytes, 10),
-		strconv.FormatInt(result.CompressedBytes, 10),
-		fmt.Sprintf("%.4f", result.CompressionRatio),
-		fmt.Sprintf("%.3f", result.CompressionTimeS),
-		fmt.Sprintf("%.3f", result.DecompressionTimeS),
-		fmt.Sprintf("%.2f", result.CompressionSpeedMBs),
-		fmt.Sprintf("%.2f", result.DecompressionSpeedMBs),
-		fmt.Sprintf("%.2f", result.CompressionMaxRSSMB),
-		fmt.Sprintf("%.2f", result.DecompressionMaxRSSMB),
-		strconv.FormatBool(result.Verified),
-		strconv.Itoa(result.Iteration),
-	}
+	row := csvRow(result)
 	err := r.csvWriter.Write(row)
 	if err != nil {
 		fmt.Printf("Failed to write CSV row: %v\n", err)
@@ -122,15 +147,17 @@ func (r *Runner) writeResult(result Result) {
 	}
 }
 
-// PrecomputeHashes computes file hashes upfront for verification
+// PrecomputeHashes computes file hashes upfront for verification. The
+// hashes also fill the file_hash column that lets compare match inputs
+// across machines, so runs without verification leave it empty.
 func (r *Runner) PrecomputeHashes() error {
 	if !r.config.VerifyDecompression {
 		return nil
 	}
 
 	fmt.Println("\n=== Pre-computing file hashes ===")
-	for _, filePath := range r.config.Files {
-		fmt.Printf("Hashing %s... ", filepath.Base(filePath))
+	for _, filePath := range r.inputs {
+		fmt.Printf("Hashing %s... ", r.displayName(filePath))
 		hash, err := util.ComputeFileHash(filePath)
 		if err != nil {
 			fmt.Printf("failed: %v\n", err)
@@ -160,36 +187,95 @@ func (r *Runner) GetAvailableCodecs() []codec.Codec {
 	return available
 }
 
-// Run executes the full benchmark suite
-func (r *Runner) Run() error {
+// Run executes the full benchmark suite. When ctx is cancelled, running
+// codecs are killed, no further jobs start, and Run returns ctx's error after
+// the completed results have been recorded.
+func (r *Runner) Run(ctx context.Context) error {
+	defer r.removeGenerated()
+	if err := r.generateInputs(); err != nil {
+		return err
+	}
+	if r.config.InputMode == InputModeConcat {
+		if err := r.concatInputs(); err != nil {
+			return err
+		}
+	}
 	if err := r.PrecomputeHashes(); err != nil {
 		return err
 	}
 
 	codecs := r.GetAvailableCodecs()
+	r.codecs = codecs
 	if len(codecs) == 0 {
 		return fmt.Errorf("no codecs available")
 	}
 
-	fmt.Printf("\n=== Benchmarking %d file(s) with %d codec(s) ===\n", len(r.config.Files), len(codecs))
+	defer r.removeDictionaries()
+	dicts, err := r.prepareDictionaries(ctx, codecs)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n=== Benchmarking %d file(s) with %d codec(s) ===\n", len(r.inputs), len(codecs))
+	for _, c := range codecs {
+		if caps := codec.Probe(c); caps.Version != "" {
+			fmt.Printf("  %s %s\n", c.Name(), caps.Version)
+		}
+	}
 
-	// Build job queue
+	// Build job queue, leaving out configurations that need flags the
+	// installed binary lacks
 	jobs := make([]job, 0)
-	for _, filePath := range r.config.Files {
+	skipped := 0
+	warned := make(map[string]bool)
+	for fileIndex, filePath := range r.inputs {
 		for _, c := range codecs {
-			for _, level := range c.Levels() {
-				for iter := 1; iter <= r.config.Iterations; iter++ {
-					jobs = append(jobs, job{
-						filePath:  filePath,
-						codec:     c,
-						level:     level,
-						iteration: iter,
-					})
+			for _, threads := range r.threadsFor(c) {
+				for _, lv := range r.levelsFor(c) {
+					level := lv.N
+					for _, params := range r.config.Params[c.Name()].CombinationsWith(lv.Params) {
+						if missing := codec.UnsupportedFlags(c, level, params); len(missing) > 0 {
+							msg := fmt.Sprintf("Skipping %s level %d: %s does not support %s",
+								c.Name(), level, c.Binary(), strings.Join(missing, ", "))
+							if !warned[msg] {
+								warned[msg] = true
+								fmt.Println(msg)
+							}
+							continue
+						}
+						for _, dict := range dicts[c.Name()] {
+							for _, records := range r.recordSplits() {
+								for iter := 1; iter <= r.config.Iterations; iter++ {
+									j := job{
+										filePath:          filePath,
+										fileIndex:         fileIndex,
+										label:             r.labels[filePath],
+										codec:             c,
+										level:             level,
+										params:            params,
+										dict:              dict,
+										records:           records,
+										iteration:         iter,
+										compressThreads:   threads[0],
+										decompressThreads: threads[1],
+									}
+									if r.completed[j.key()] {
+										skipped++
+										continue
+									}
+									jobs = append(jobs, j)
+								}
+							}
+						}
+					}
 				}
 			}
 		}
 	}
 
+	if r.config.Resume {
+		fmt.Printf("Resuming: %d run(s) already in %s\n", skipped, r.config.OutputCSV)
+	}
 	fmt.Printf("Total benchmark runs: %d\n", len(jobs))
 
 	// Process jobs in parallel
@@ -205,7 +291,10 @@ func (r *Runner) Run() error {
 		go func(workerID int) {
 			defer wg.Done()
 			for j := range jobChan {
-				result := r.runSingleBenchmark(j)
+				if ctx.Err() != nil {
+					return
+				}
+				result := r.runSingleBenchmark(ctx, j)
 				if result != nil {
 					r.resultsMux.Lock()
 					r.results = append(r.results, *result)
@@ -217,16 +306,100 @@ func (r *Runner) Run() error {
 	}
 
 	wg.Wait()
+
+	if summaries := r.Summaries(); len(summaries) > 0 {
+		fmt.Println("\n=== Summary ===")
+		PrintSummaryTable(os.Stdout, summaries)
+		if r.config.Records.Kind != "" {
+			fmt.Printf("\n=== Record latency (%s) ===\n", r.config.Records)
+			PrintRecordTable(os.Stdout, summaries)
+		}
+		if len(r.config.ChunkSizes) > 0 {
+			fmt.Println("\n=== Chunked compression ===")
+			PrintChunkTable(os.Stdout, summaries)
+		}
+	}
+	if len(r.inputs) > 1 {
+		if totals := r.CorpusSummaries(); len(totals) > 0 {
+			fmt.Printf("\n=== Corpus totals (%d files) ===\n", len(r.inputs))
+			PrintCorpusTable(os.Stdout, totals)
+		}
+	}
+	return ctx.Err()
+}
+
+// Summaries aggregates the completed results across iterations
+func (r *Runner) Summaries() []Summary {
+	r.resultsMux.Lock()
+	defer r.resultsMux.Unlock()
+	return Aggregate(r.results)
+}
+
+// CorpusSummaries totals the completed results across input files
+func (r *Runner) CorpusSummaries() []CorpusSummary {
+	r.resultsMux.Lock()
+	defer r.resultsMux.Unlock()
+	return AggregateCorpus(r.results)
+}
+
+// WriteSummaries writes aggregated results to the configured summary files
+func (r *Runner) WriteSummaries() error {
+	if r.config.CorpusSummary != "" {
+		if err := WriteCorpusSummary(r.config.CorpusSummary, r.CorpusSummaries()); err != nil {
+			return err
+		}
+	}
+	if r.config.SummaryCSV == "" && r.config.SummaryJSON == "" {
+		return nil
+	}
+
+	summaries := r.Summaries()
+	if r.config.SummaryCSV != "" {
+		if err := WriteSummaryCSV(r.config.SummaryCSV, summaries); err != nil {
+			return err
+		}
+	}
+	if r.config.SummaryJSON != "" {
+		if err := WriteSummaryJSON(r.config.SummaryJSON, summaries); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// WriteJSONSummary writes results to JSON file
+// threadsFor returns the (compression, decompression) thread count pairs to
+// run c with. Codecs without threading support collapse to a single point.
+func (r *Runner) threadsFor(c codec.Codec) [][2]int {
+	if !c.SupportsThreading() {
+		return [][2]int{{1, 1}}
+	}
+	pairs := make([][2]int, 0, len(r.config.CompressThreads)*len(r.config.DecompressThreads))
+	for _, ct := range r.config.CompressThreads {
+		for _, dt := range r.config.DecompressThreads {
+			pairs = append(pairs, [2]int{ct, dt})
+		}
+	}
+	return pairs
+}
+
+// levelsFor returns the levels to benchmark for c
+func (r *Runner) levelsFor(c codec.Codec) []codec.Level {
+	if levels, ok := r.config.Levels[c.Name()]; ok {
+		return levels
+	}
+	return codec.PlainLevels(c.Levels())
+}
+
+// WriteJSONSummary writes the resolved plan and the results to the JSON file
 func (r *Runner) WriteJSONSummary() error {
 	if r.config.OutputJSON == "" {
 		return nil
 	}
 
-	data, err := json.MarshalIndent(r.results, "", "  ")
+	data, err := json.MarshalIndent(ResultsFile{
+		Plan:    PlanFromConfig(r.config, r.codecs),
+		Results: r.results,
+	}, "", "  ")
 	if err != nil {
 		return err
 	}
@@ -234,105 +407,430 @@ func (r *Runner) WriteJSONSummary() error {
 	return os.WriteFile(r.config.OutputJSON, data, 0644)
 }
 
-func (r *Runner) runSingleBenchmark(j job) *Result {
+func (r *Runner) runSingleBenchmark(ctx context.Context, j job) *Result {
 	timestamp := time.Now().Unix()
 	c := j.codec.(codec.Codec)
-	runID := fmt.Sprintf("%d_%s_%s_%d_%d", timestamp, filepath.Base(j.filePath), c.Name(), j.level, j.iteration)
+	params := j.params.String()
+	dict := j.dict.dictPath()
+	runID := fmt.Sprintf("%d_%d-%s_%s_%d_%d_t%d-%d", timestamp, j.fileIndex, filepath.Base(j.filePath), c.Name(), j.level, j.iteration, j.compressThreads, j.decompressThreads)
+
+	desc := fmt.Sprintf("%s level %d", c.Name(), j.level)
+	if params != "" {
+		desc += " [" + params + "]"
+	}
+	if j.dict != nil {
+		desc += " dict=" + util.FormatSize(j.dict.bytes)
+	}
+	if j.records.Kind != "" {
+		desc += " records=" + j.records.String()
+	}
+	fmt.Printf("[%d/%d] %s - %s, threads %d/%d\n", j.iteration, r.config.Iterations, r.displayName(j.filePath), desc, j.compressThreads, j.decompressThreads)
+
+	// Setup paths. Inputs from different directories can share a base name,
+	// so the input's index keeps parallel workers from sharing temp files.
+	base := fmt.Sprintf("%d-%s.%s.%d.%d.t%d-%d", j.fileIndex, filepath.Base(j.filePath), c.Name(), j.level, j.iteration, j.compressThreads, j.decompressThreads)
+	if params != "" {
+		tag := paramsTag(params)
+		runID += "_" + tag
+		base += "." + tag
+	}
+	if j.dict != nil {
+		tag := fmt.Sprintf("dict%d", j.dict.bytes)
+		runID += "_" + tag
+		base += "." + tag
+	}
+	if j.records.Kind != "" {
+		tag := paramsTag(j.records.String())
+		runID += "_" + tag
+		base += "." + tag
+	}
+	compOut := filepath.Join(r.config.TmpDir, base+c.Extension())
+	decompOut := filepath.Join(r.config.TmpDir, base+".decompressed")
 
-	fmt.Printf("[%d/%d] %s - %s level %d\n", j.iteration, r.config.Iterations, filepath.Base(j.filePath), c.Name(), j.level)
+	compThreads := j.compressThreads
+	decompThreads := j.decompressThreads
 
-	// Setup paths
-	compOut := filepath.Join(r.config.TmpDir, fmt.Sprintf("%s.%s.%d.%d%s", filepath.Base(j.filePath), c.Name(), j.level, j.iteration, c.Extension()))
-	decompOut := filepath.Join(r.config.TmpDir, fmt.Sprintf("%s.decompressed.%d", filepath.Base(j.filePath), j.iteration))
+	result := &Result{
+		RunID:             runID,
+		Algorithm:         c.Name(),
+		Level:             j.level,
+		Params:            params,
+		CodecVersion:      codec.Probe(c).Version,
+		DictionaryBytes:   j.dict.dictBytes(),
+		DictionaryTrainS:  j.dict.trainSeconds(),
+		CompressThreads:   compThreads,
+		DecompressThreads: decompThreads,
+		FilePath:          j.input(),
+		FileHash:          r.fileHashes[j.filePath],
+		Iteration:         j.iteration,
+		WarmupRuns:        r.config.WarmupRuns,
+		Status:            StatusOK,
+	}
 
-	// Determine thread counts
-	compThreads := r.config.CompressThreads
-	decompThreads := r.config.DecompressThreads
-	if !c.SupportsThreading() {
-		compThreads = 1
-		decompThreads = 1
+	// The timeout covers warm-up, compression and decompression together
+	runCtx := ctx
+	if r.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, r.config.Timeout)
+		defer cancel()
+	}
+
+	if j.records.Kind != "" {
+		return r.runRecordBenchmark(ctx, runCtx, j, result, compOut, decompOut)
 	}
 
 	// Get uncompressed size
 	uncompSize, err := util.FileSize(j.filePath)
 	if err != nil {
-		fmt.Printf("  ! Failed to get file size: %v\n", err)
-		return nil
+		return r.recordFailure(ctx, runCtx, result, "reading input size", err)
 	}
+	result.UncompressedBytes = uncompSize
 
-	// Compression
-	compCmd := c.CompressCommand(j.level, compThreads, j.filePath, compOut)
-	compTime, err := util.RunCommand(c.Binary(), compCmd, compOut)
-	if err != nil {
-		fmt.Printf("  ! Compression failed: %v\n", err)
-		if removeErr := os.Remove(compOut); removeErr != nil && !os.IsNotExist(removeErr) {
-			fmt.Printf("  warning: failed to remove compressed file: %v\n", removeErr)
+	stream := r.config.Streaming && canStream(c)
+	if r.config.Streaming && !stream {
+		fmt.Printf("  %s cannot stream, using temporary files\n", c.Name())
+	}
+	if !stream {
+		defer removeIfExists(compOut)
+		defer removeIfExists(decompOut)
+	}
+
+	// Untimed warm-up runs
+	for i := 0; i < r.config.WarmupRuns; i++ {
+		if err := r.warmUp(runCtx, c, j.level, compThreads, decompThreads, j.params, dict, j.filePath, compOut, decompOut, stream); err != nil {
+			if runCtx.Err() != nil {
+				return r.recordFailure(ctx, runCtx, result, "warm-up", err)
+			}
+			fmt.Printf("  warning: warm-up run failed: %v\n", err)
+			break
 		}
-		return nil
 	}
 
-	compSize, err := util.FileSize(compOut)
+	result.CacheState = r.prepareCache(j.filePath)
+
+	// Compression. Streaming runs keep the compressed output in memory.
+	var compMeasure util.Measurement
+	var compressed bytes.Buffer
+	if stream {
+		compMeasure, err = compressStream(runCtx, c, j.level, compThreads, j.params, dict, j.filePath, &compressed)
+	} else {
+		compMeasure, err = compress(runCtx, c, j.level, compThreads, j.params, dict, j.filePath, compOut)
+	}
 	if err != nil {
-		fmt.Printf("  ! Failed to get compressed size: %v\n", err)
-		if removeErr := os.Remove(compOut); removeErr != nil && !os.IsNotExist(removeErr) {
-			fmt.Printf("  warning: failed to remove compressed file: %v\n", removeErr)
+		return r.recordFailure(ctx, runCtx, result, "compression", err)
+	}
+	compSize := int64(compressed.Len())
+	if !stream {
+		compSize, err = util.FileSize(compOut)
+		if err != nil {
+			return r.recordFailure(ctx, runCtx, result, "reading compressed size", err)
 		}
-		return nil
 	}
 
 	// Calculate compression metrics
-	compTimeSec := compTime.Seconds()
-	compSpeedMBs := float64(uncompSize) / (1024 * 1024) / compTimeSec
-	ratio := float64(compSize) / float64(uncompSize)
-
-	result := &Result{
-		RunID:               runID,
-		Algorithm:           c.Name(),
-		Level:               j.level,
-		CompressThreads:     compThreads,
-		DecompressThreads:   decompThreads,
-		FilePath:            j.filePath,
-		UncompressedBytes:   uncompSize,
-		CompressedBytes:     compSize,
-		CompressionRatio:    ratio,
-		CompressionTimeS:    compTimeSec,
-		CompressionSpeedMBs: compSpeedMBs,
-		Iteration:           j.iteration,
+	compTimeSec := compMeasure.Wall.Seconds()
+	result.CompressedBytes = compSize
+	result.CompressionRatio = float64(compSize) / float64(uncompSize)
+	result.CompressionTimeS = compTimeSec
+	result.CompressionSpeedMBs = float64(uncompSize) / (1024 * 1024) / compTimeSec
+	result.CompressionMaxRSSMB = bytesToMB(compMeasure.MaxRSSBytes)
+	result.CompressionUserS = compMeasure.User.Seconds()
+	result.CompressionSysS = compMeasure.System.Seconds()
+	result.CompressionVolCtxSwitches = compMeasure.VoluntaryCtxSwitches
+	result.CompressionInvolCtxSwitches = compMeasure.InvoluntaryCtxSwitches
+	result.CompressionMinorFaults = compMeasure.MinorPageFaults
+	result.CompressionMajorFaults = compMeasure.MajorPageFaults
+
+	if r.config.SkipDecompression {
+		return result
 	}
 
 	// Decompression
-	if !r.config.SkipDecompression {
-		decompCmd := c.DecompressCommand(decompThreads, compOut, decompOut)
-		decompTime, err := util.RunCommand(c.Binary(), decompCmd, decompOut)
-		if err != nil {
-			fmt.Printf("  ! Decompression failed: %v\n", err)
-		} else {
-			decompTimeSec := decompTime.Seconds()
-			result.DecompressionTimeS = decompTimeSec
-			result.DecompressionSpeedMBs = float64(uncompSize) / (1024 * 1024) / decompTimeSec
-
-			// Verify if requested
-			if r.config.VerifyDecompression {
-				if origHash, ok := r.fileHashes[j.filePath]; ok {
-					decompHash, err := util.ComputeFileHash(decompOut)
-					if err == nil && decompHash == origHash {
-						result.Verified = true
-						fmt.Println("  ✓ Verified")
-					} else {
-						fmt.Println("  ! Verification failed")
-					}
+	var decompMeasure util.Measurement
+	var sink *util.HashingWriter
+	if stream {
+		sink = util.NewHashingWriter()
+		decompMeasure, err = decompressStream(runCtx, c, decompThreads, j.params, dict, bytes.NewReader(compressed.Bytes()), sink)
+	} else {
+		r.prepareCache(compOut)
+		decompMeasure, err = decompress(runCtx, c, decompThreads, j.params, dict, compOut, decompOut)
+	}
+	if err != nil {
+		return r.recordFailure(ctx, runCtx, result, "decompression", err)
+	}
+
+	decompTimeSec := decompMeasure.Wall.Seconds()
+	result.DecompressionTimeS = decompTimeSec
+	result.DecompressionSpeedMBs = float64(uncompSize) / (1024 * 1024) / decompTimeSec
+	result.DecompressionMaxRSSMB = bytesToMB(decompMeasure.MaxRSSBytes)
+	result.DecompressionUserS = decompMeasure.User.Seconds()
+	result.DecompressionSysS = decompMeasure.System.Seconds()
+	result.DecompressionVolCtxSwitches = decompMeasure.VoluntaryCtxSwitches
+	result.DecompressionInvolCtxSwitches = decompMeasure.InvoluntaryCtxSwitches
+	result.DecompressionMinorFaults = decompMeasure.MinorPageFaults
+	result.DecompressionMajorFaults = decompMeasure.MajorPageFaults
+
+	// Verify if requested
+	if r.config.VerifyDecompression {
+		if origHash, ok := r.fileHashes[j.filePath]; ok {
+			var decompHash string
+			if stream {
+				if sink.Count() == uncompSize {
+					decompHash = sink.Sum()
 				}
+			} else {
+				decompHash, err = util.ComputeFileHash(decompOut)
 			}
+			switch {
+			case err != nil:
+				result.Status = StatusVerifyFailed
+				result.Error = fmt.Sprintf("verification failed: %v", err)
+				fmt.Println("  ! Verification failed")
+			case decompHash != origHash:
+				result.Status = StatusVerifyFailed
+				result.Error = "verification failed: decompressed output does not match input"
+				fmt.Println("  ! Verification failed")
+			default:
+				result.Verified = true
+				fmt.Println("  ✓ Verified")
+			}
+		}
+	}
+
+	return result
+}
+
+// recordFailure marks result as failed or timed out at the given step. Runs
+// stopped because the whole benchmark was cancelled are not recorded, so nil
+// is returned for them.
+func (r *Runner) recordFailure(ctx, runCtx context.Context, result *Result, step string, err error) *Result {
+	if ctx.Err() != nil {
+		fmt.Printf("  ! %s cancelled\n", step)
+		return nil
+	}
+	if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+		result.Status = StatusTimeout
+		result.Error = fmt.Sprintf("%s timed out after %s", step, r.config.Timeout)
+	} else {
+		result.Status = StatusFailed
+		result.Error = fmt.Sprintf("%s failed: %v", step, err)
+	}
+	fmt.Printf("  ! %s\n", result.Error)
+	return result
+}
+
+// warmUp performs one untimed compression and decompression of input
+func (r *Runner) warmUp(ctx context.Context, c codec.Codec, level, compThreads, decompThreads int, params codec.Params, dict, input, compOut, decompOut string, stream bool) error {
+	if stream {
+		var compressed bytes.Buffer
+		if _, err := compressStream(ctx, c, level, compThreads, params, dict, input, &compressed); err != nil {
+			return err
+		}
+		if r.config.SkipDecompression {
+			return nil
+		}
+		_, err := decompressStream(ctx, c, decompThreads, params, dict, &compressed, io.Discard)
+		return err
+	}
+
+	defer removeIfExists(compOut)
+	if _, err := compress(ctx, c, level, compThreads, params, dict, input, compOut); err != nil {
+		return err
+	}
+	if r.config.SkipDecompression {
+		return nil
+	}
+	defer removeIfExists(decompOut)
+	_, err := decompress(ctx, c, decompThreads, params, dict, compOut, decompOut)
+	return err
+}
+
+// prepareCache puts path into the configured page cache state and returns
+// the state that was achieved
+func (r *Runner) prepareCache(path string) string {
+	switch r.config.CacheMode {
+	case CacheModeDrop:
+		if err := util.DropFileCache(path); err != nil {
+			fmt.Printf("  warning: failed to drop page cache: %v\n", err)
+			return CacheStateUnmanaged
 		}
-		if err := os.Remove(decompOut); err != nil && !os.IsNotExist(err) {
-			fmt.Printf("  warning: failed to remove decompressed file: %v\n", err)
+		return CacheStateCold
+	case CacheModeWarm:
+		if err := util.WarmFileCache(path); err != nil {
+			fmt.Printf("  warning: failed to warm page cache: %v\n", err)
+			return CacheStateUnmanaged
 		}
+		return CacheStateWarm
+	}
+	return CacheStateUnmanaged
+}
+
+// generateInputs writes each configured generator spec to the temporary
+// directory and adds the files to the inputs
+func (r *Runner) generateInputs() error {
+	if len(r.config.Generate) == 0 {
+		return nil
+	}
+	dir := filepath.Join(r.config.TmpDir, "corpus")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create corpus directory: %w", err)
 	}
 
-	// Cleanup
-	if err := os.Remove(compOut); err != nil && !os.IsNotExist(err) {
-		fmt.Printf("  warning: failed to remove compressed file: %v\n", err)
+	fmt.Println("\n=== Generating inputs ===")
+	generated := make(map[string]bool, len(r.config.Generate))
+	for _, spec := range r.config.Generate {
+		if generated[spec.String()] {
+			fmt.Printf("Skipping duplicate input %s\n", spec)
+			continue
+		}
+		generated[spec.String()] = true
+		path := filepath.Join(dir, spec.FileName())
+		fmt.Printf("Generating %s... ", spec)
+		start := time.Now()
+		if err := corpus.WriteFile(path, spec); err != nil {
+			fmt.Println("failed")
+			return err
+		}
+		fmt.Printf("✓ (%s)\n", time.Since(start).Round(time.Millisecond))
+		r.inputs = append(r.inputs, path)
+		r.labels[path] = spec.String()
 	}
+	return nil
+}
 
-	return result
+// concatInputs replaces the inputs with a single tar stream of all of them,
+// recorded under the input patterns it was built from
+func (r *Runner) concatInputs() error {
+	path := filepath.Join(r.config.TmpDir, "corpus", "concat.tar")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create corpus directory: %w", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	r.labels[path] = corpus.ConcatLabel(r.inputPatterns())
+
+	fmt.Printf("\nConcatenating %d input(s) into one tar stream... ", len(r.inputs))
+	if err := corpus.TarFiles(f, r.inputs); err != nil {
+		_ = f.Close()
+		fmt.Println("failed")
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Println("✓")
+	r.inputs = []string{path}
+	return nil
+}
+
+// inputPatterns lists the configured file patterns and generator specs
+func (r *Runner) inputPatterns() []string {
+	patterns := slices.Clone(r.config.Files)
+	for _, spec := range r.config.Generate {
+		patterns = append(patterns, spec.String())
+	}
+	return patterns
+}
+
+// removeGenerated deletes the files written by generateInputs and
+// concatInputs
+func (r *Runner) removeGenerated() {
+	for path := range r.labels {
+		removeIfExists(path)
+	}
+}
+
+// displayName returns the name an input is shown under in progress output
+func (r *Runner) displayName(path string) string {
+	if label, ok := r.labels[path]; ok {
+		return label
+	}
+	return filepath.Base(path)
+}
+
+// paramsTag turns a params string into a form usable in file names
+func paramsTag(params string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' {
+			return r
+		}
+		return '_'
+	}, params)
+}
+
+// removeIfExists removes a temporary file, warning on failure
+func removeIfExists(path string) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		fmt.Printf("  warning: failed to remove %s: %v\n", filepath.Base(path), err)
+	}
+}
+
+// compress compresses input into output, either in-process or by executing
+// the codec's binary
+func compress(ctx context.Context, c codec.Codec, level, threads int, params codec.Params, dict, input, output string) (util.Measurement, error) {
+	if ip, ok := c.(codec.InProcessCodec); ok {
+		return runInProcess(ctx, input, output, func(dst io.Writer, src io.Reader) error {
+			return ip.Compress(dst, src, level, threads)
+		})
+	}
+	return runExternal(ctx, c, codec.CompressArgs(c, level, threads, params, dict, input, output), input, output)
+}
+
+// decompress decompresses input into output, either in-process or by
+// executing the codec's binary
+func decompress(ctx context.Context, c codec.Codec, threads int, params codec.Params, dict, input, output string) (util.Measurement, error) {
+	if ip, ok := c.(codec.InProcessCodec); ok {
+		return runInProcess(ctx, input, output, func(dst io.Writer, src io.Reader) error {
+			return ip.Decompress(dst, src, threads)
+		})
+	}
+	return runExternal(ctx, c, codec.DecompressArgs(c, threads, params, dict, input, output), input, output)
+}
+
+// runExternal executes the codec's binary, connecting its standard streams
+// to input and output as its I/O mode requires
+func runExternal(ctx context.Context, c codec.Codec, args []string, input, output string) (util.Measurement, error) {
+	switch c.IOMode() {
+	case codec.IOStdout:
+		return util.RunCommand(ctx, c.Binary(), args, "", output)
+	case codec.IOStdinStdout:
+		return util.RunCommand(ctx, c.Binary(), args, input, output)
+	case codec.IOFile:
+		return util.RunCommand(ctx, c.Binary(), args, "", "")
+	}
+	return util.Measurement{}, fmt.Errorf("%s: unsupported I/O mode %s", c.Name(), c.IOMode())
+}
+
+// runInProcess opens input and output and measures fn streaming between them.
+// Opening and closing the files is not part of the measurement.
+func runInProcess(ctx context.Context, input, output string, fn func(dst io.Writer, src io.Reader) error) (util.Measurement, error) {
+	in, err := os.Open(input)
+	if err != nil {
+		return util.Measurement{}, err
+	}
+	defer func() {
+		if closeErr := in.Close(); closeErr != nil {
+			fmt.Printf("warning: failed to close input file: %v\n", closeErr)
+		}
+	}()
+
+	out, err := os.Create(output)
+	if err != nil {
+		return util.Measurement{}, err
+	}
+
+	m, err := util.MeasureFunc(func() error {
+		return fn(out, util.ContextReader(ctx, in))
+	})
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return m, err
+}
+
+// bytesToMB converts a byte count to mebibytes
+func bytesToMB(n int64) float64 {
+	return float64(n) / (1024 * 1024)
 }
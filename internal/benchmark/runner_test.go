@@ -0,0 +1,316 @@
+package benchmark
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aomarai/compstat/internal/codec"
+	"github.com/aomarai/compstat/internal/corpus"
+	"github.com/aomarai/compstat/internal/util"
+)
+
+// fakeCodec is an in-process codec that copies data unchanged, optionally
+// failing or stalling
+type fakeCodec struct {
+	compressErr error
+	stall       bool
+	corrupt     bool
+}
+
+func (f *fakeCodec) Name() string                                                { return "fake" }
+func (f *fakeCodec) Binary() string                                              { return "" }
+func (f *fakeCodec) Extension() string                                           { return ".fake" }
+func (f *fakeCodec) Levels() []int                                               { return []int{1} }
+func (f *fakeCodec) IsAvailable() bool                                           { return true }
+func (f *fakeCodec) IOMode() codec.IOMode                                        { return codec.IOInProcess }
+func (f *fakeCodec) SupportsThreading() bool                                     { return false }
+func (f *fakeCodec) CompressCommand(level, threads int, in, out string) []string { return nil }
+func (f *fakeCodec) DecompressCommand(threads int, in, out string) []string      { return nil }
+
+func (f *fakeCodec) Compress(dst io.Writer, src io.Reader, level, threads int) error {
+	if f.compressErr != nil {
+		return f.compressErr
+	}
+	if f.stall {
+		buf := make([]byte, 1)
+		for {
+			if _, err := src.Read(buf); err != nil && err != io.EOF {
+				return err
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	_, err := io.Copy(dst, src)
+	return err
+}
+
+func (f *fakeCodec) Decompress(dst io.Writer, src io.Reader, threads int) error {
+	if f.corrupt {
+		_, err := dst.Write([]byte("corrupt"))
+		return err
+	}
+	_, err := io.Copy(dst, src)
+	return err
+}
+
+func newTestRunner(t *testing.T, config Config) (*Runner, string) {
+	t.Helper()
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.txt")
+	if err := os.WriteFile(input, []byte("hello compstat"), 0644); err != nil {
+		t.Fatalf("Failed to create input: %v", err)
+	}
+	config.Files = []string{input}
+	config.TmpDir = filepath.Join(dir, "tmp")
+	config.OutputCSV = filepath.Join(dir, "results.csv")
+	config.Iterations = 1
+	config.VerifyDecompression = true
+
+	runner, err := NewRunner(config)
+	if err != nil {
+		t.Fatalf("NewRunner failed: %v", err)
+	}
+	t.Cleanup(runner.Close)
+	if err := runner.PrecomputeHashes(); err != nil {
+		t.Fatalf("PrecomputeHashes failed: %v", err)
+	}
+	return runner, input
+}
+
+func TestRunSingleBenchmarkStatuses(t *testing.T) {
+	tests := []struct {
+		name    string
+		codec   *fakeCodec
+		timeout time.Duration
+		status  string
+	}{
+		{"ok", &fakeCodec{}, 0, StatusOK},
+		{"failed", &fakeCodec{compressErr: errors.New("boom")}, 0, StatusFailed},
+		{"timeout", &fakeCodec{stall: true}, 50 * time.Millisecond, StatusTimeout},
+		{"verify failed", &fakeCodec{corrupt: true}, 0, StatusVerifyFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, input := newTestRunner(t, Config{Timeout: tt.timeout})
+			result := runner.runSingleBenchmark(context.Background(), job{filePath: input, codec: tt.codec, level: 1, iteration: 1})
+			if result == nil {
+				t.Fatal("Expected a result row")
+			}
+			if result.Status != tt.status {
+				t.Errorf("Expected status %s, got %s (%s)", tt.status, result.Status, result.Error)
+			}
+			if tt.status != StatusOK && result.Error == "" {
+				t.Error("Expected an error message")
+			}
+			if tt.status == StatusOK && !result.Verified {
+				t.Error("Expected successful run to be verified")
+			}
+		})
+	}
+}
+
+func TestRunSingleBenchmarkCancelledIsNotRecorded(t *testing.T) {
+	runner, input := newTestRunner(t, Config{})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result := runner.runSingleBenchmark(ctx, job{filePath: input, codec: &fakeCodec{stall: true}, level: 1, iteration: 1})
+	if result != nil {
+		t.Errorf("Expected no result for a cancelled run, got status %s", result.Status)
+	}
+}
+
+func TestPrecomputeHashesOnlyWhenVerifying(t *testing.T) {
+	runner, input := newTestRunner(t, Config{})
+	if runner.fileHashes[input] == "" {
+		t.Fatal("Expected the input to be hashed for verification")
+	}
+
+	runner.config.VerifyDecompression = false
+	runner.fileHashes = make(map[string]string)
+	if err := runner.PrecomputeHashes(); err != nil {
+		t.Fatalf("PrecomputeHashes failed: %v", err)
+	}
+	if len(runner.fileHashes) != 0 {
+		t.Errorf("Expected no hashes without verification, got %v", runner.fileHashes)
+	}
+	result := runner.runSingleBenchmark(context.Background(), job{filePath: input, codec: &fakeCodec{}, level: 1, iteration: 1})
+	if result == nil || result.FileHash != "" || result.Verified {
+		t.Errorf("Expected an unverified run without file_hash, got %+v", result)
+	}
+}
+
+func TestGeneratedInputsRecordSpec(t *testing.T) {
+	spec := corpus.Spec{Kind: "text", Size: 4096, Seed: corpus.DefaultSeed}
+	runner, _ := newTestRunner(t, Config{Generate: []corpus.Spec{spec}})
+	if err := runner.generateInputs(); err != nil {
+		t.Fatalf("generateInputs failed: %v", err)
+	}
+	if len(runner.inputs) != 2 {
+		t.Fatalf("Expected the file and the generated input, got %v", runner.inputs)
+	}
+	generated := runner.inputs[1]
+	if err := runner.PrecomputeHashes(); err != nil {
+		t.Fatalf("PrecomputeHashes failed: %v", err)
+	}
+
+	j := job{filePath: generated, label: runner.labels[generated], codec: &fakeCodec{}, level: 1, iteration: 1}
+	result := runner.runSingleBenchmark(context.Background(), j)
+	if result == nil || !result.Verified {
+		t.Fatalf("Expected a verified result, got %+v", result)
+	}
+	if result.FilePath != "text:4KiB" || result.UncompressedBytes != 4096 {
+		t.Errorf("Expected the spec as file path and 4096 bytes, got %q and %d", result.FilePath, result.UncompressedBytes)
+	}
+
+	runner.removeGenerated()
+	if _, err := os.Stat(generated); !os.IsNotExist(err) {
+		t.Error("Generated input was not removed")
+	}
+}
+
+func TestGeneratedTarInputsWithSameBaseName(t *testing.T) {
+	root := t.TempDir()
+	specs := make([]corpus.Spec, 0)
+	for _, parent := range []string{"a", "b"} {
+		dir := filepath.Join(root, parent, "src")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "file"), []byte(parent), 0644); err != nil {
+			t.Fatal(err)
+		}
+		spec, err := corpus.ParseSpec("tar:" + dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		specs = append(specs, spec)
+	}
+	// A repeated spec is generated once
+	specs = append(specs, specs[1])
+
+	runner, _ := newTestRunner(t, Config{Generate: specs})
+	if err := runner.generateInputs(); err != nil {
+		t.Fatalf("generateInputs failed: %v", err)
+	}
+	if len(runner.inputs) != 3 {
+		t.Fatalf("Expected the file and both tar inputs, got %v", runner.inputs)
+	}
+	if runner.inputs[1] == runner.inputs[2] {
+		t.Errorf("Both tar inputs were written to %s", runner.inputs[1])
+	}
+	for i, spec := range specs[:2] {
+		if got := runner.labels[runner.inputs[i+1]]; got != spec.String() {
+			t.Errorf("Label = %q, want %q", got, spec)
+		}
+	}
+}
+
+func TestInputsWithSameBaseNameRunInParallel(t *testing.T) {
+	if _, err := exec.LookPath("zstd"); err != nil {
+		t.Skip("zstd not available")
+	}
+	runner, _ := newTestRunner(t, Config{Codecs: []string{"zstd"}, Levels: map[string][]codec.Level{"zstd": {{N: 19}}},
+		Parallelism: 2, CompressThreads: []int{1}, DecompressThreads: []int{1}})
+	root := t.TempDir()
+	runner.inputs = nil
+	for _, parent := range []string{"a", "b"} {
+		path := filepath.Join(root, parent, "app.log")
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		data := bytes.Repeat([]byte(parent+" log line\n"), 50000)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		runner.inputs = append(runner.inputs, path)
+	}
+	if err := runner.PrecomputeHashes(); err != nil {
+		t.Fatalf("PrecomputeHashes failed: %v", err)
+	}
+
+	if err := runner.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(runner.results) != 2 {
+		t.Fatalf("Expected two results, got %d", len(runner.results))
+	}
+	if runner.results[0].RunID == runner.results[1].RunID {
+		t.Errorf("Both inputs got run ID %s", runner.results[0].RunID)
+	}
+	for _, res := range runner.results {
+		if res.Status != StatusOK || !res.Verified {
+			t.Errorf("%s: expected a verified result, got %s (%s)", res.FilePath, res.Status, res.Error)
+		}
+	}
+}
+
+func TestConfigCodecStdoutCapture(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat not available")
+	}
+	// The codec writes to stdout, which the runner must capture into the
+	// output file, for the default file-based runs and for -stream
+	config := filepath.Join(t.TempDir(), "codecs.yaml")
+	content := `codecs:
+  - name: config-cat
+    binary: cat
+    levels: "1"
+    stdout: true
+    compress: ["{input}"]
+    decompress: ["{input}"]
+    stream_compress: ["-"]
+    stream_decompress: ["-"]
+`
+	if err := os.WriteFile(config, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := codec.LoadExternalCodecs(config); err != nil {
+		t.Fatalf("LoadExternalCodecs failed: %v", err)
+	}
+	defer delete(codec.Registry, "config-cat")
+
+	for _, stream := range []bool{false, true} {
+		runner, _ := newTestRunner(t, Config{Codecs: []string{"config-cat"}, Streaming: stream, Parallelism: 1,
+			CompressThreads: []int{1}, DecompressThreads: []int{1}})
+		if err := runner.Run(context.Background()); err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if len(runner.results) != 1 {
+			t.Fatalf("Expected one result, got %d", len(runner.results))
+		}
+		// "hello compstat" is 14 bytes, and cat leaves it unchanged
+		res := runner.results[0]
+		if res.Status != StatusOK || !res.Verified || res.CompressedBytes != 14 {
+			t.Errorf("stream=%v: expected a verified 14-byte result, got %+v", stream, res)
+		}
+	}
+}
+
+func TestConcatInputs(t *testing.T) {
+	spec := corpus.Spec{Kind: "zeros", Size: 1024, Seed: corpus.DefaultSeed}
+	runner, input := newTestRunner(t, Config{Generate: []corpus.Spec{spec}, InputMode: InputModeConcat})
+	if err := runner.generateInputs(); err != nil {
+		t.Fatalf("generateInputs failed: %v", err)
+	}
+	if err := runner.concatInputs(); err != nil {
+		t.Fatalf("concatInputs failed: %v", err)
+	}
+	if len(runner.inputs) != 1 {
+		t.Fatalf("Expected a single concatenated input, got %v", runner.inputs)
+	}
+	if got, want := runner.labels[runner.inputs[0]], "concat:"+input+",zeros:1KiB"; got != want {
+		t.Errorf("Label = %q, want %q", got, want)
+	}
+	if size, _ := util.FileSize(runner.inputs[0]); size < 1024+int64(len("hello compstat")) {
+		t.Errorf("Concatenated input has only %d bytes", size)
+	}
+}
@@ -0,0 +1,77 @@
+package benchmark
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/aomarai/compstat/internal/codec"
+)
+
+// selfTestData returns a small, moderately compressible sample
+func selfTestData() []byte {
+	var buf bytes.Buffer
+	for i := 0; buf.Len() < 64*1024; i++ {
+		_, _ = fmt.Fprintf(&buf, "compstat self-test line %d: %x\n", i, i*2654435761)
+	}
+	return buf.Bytes()
+}
+
+// SelfTest compresses and decompresses a small sample with c in its declared
+// I/O mode, and through stdin/stdout if it can stream, checking that each
+// step produces output and that the data round-trips. Temporary files are
+// created in dir.
+func SelfTest(ctx context.Context, c codec.Codec, dir string) error {
+	data := selfTestData()
+	base := filepath.Join(dir, "selftest."+c.Name())
+	input := base + ".in"
+	compOut := base + c.Extension()
+	decompOut := base + ".out"
+	defer removeIfExists(input)
+	defer removeIfExists(compOut)
+	defer removeIfExists(decompOut)
+
+	if err := os.WriteFile(input, data, 0644); err != nil {
+		return err
+	}
+	level := c.Levels()[0]
+	mode := c.IOMode()
+
+	if _, err := compress(ctx, c, level, 1, nil, "", input, compOut); err != nil {
+		return fmt.Errorf("compression in %s mode failed: %w", mode, err)
+	}
+	if size, err := os.Stat(compOut); err != nil || size.Size() == 0 {
+		return fmt.Errorf("compression in %s mode produced no output at %s", mode, compOut)
+	}
+	if _, err := decompress(ctx, c, 1, nil, "", compOut, decompOut); err != nil {
+		return fmt.Errorf("decompression in %s mode failed: %w", mode, err)
+	}
+	got, err := os.ReadFile(decompOut)
+	if err != nil {
+		return fmt.Errorf("decompression in %s mode produced no output at %s", mode, decompOut)
+	}
+	if !bytes.Equal(got, data) {
+		return fmt.Errorf("decompression in %s mode did not reproduce the input (%d of %d bytes)", mode, len(got), len(data))
+	}
+
+	if !canStream(c) {
+		return nil
+	}
+	var compressed bytes.Buffer
+	if _, err := compressStream(ctx, c, level, 1, nil, "", input, &compressed); err != nil {
+		return fmt.Errorf("stream compression failed: %w", err)
+	}
+	if compressed.Len() == 0 {
+		return fmt.Errorf("stream compression produced no output")
+	}
+	var decompressed bytes.Buffer
+	if _, err := decompressStream(ctx, c, 1, nil, "", &compressed, &decompressed); err != nil {
+		return fmt.Errorf("stream decompression failed: %w", err)
+	}
+	if !bytes.Equal(decompressed.Bytes(), data) {
+		return fmt.Errorf("stream decompression did not reproduce the input")
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aomarai/compstat/internal/codec"
+)
+
+func TestSelfTestRegisteredCodecs(t *testing.T) {
+	for name, c := range codec.Registry {
+		t.Run(name, func(t *testing.T) {
+			if !c.IsAvailable() {
+				t.Skipf("%s not found", c.Binary())
+			}
+			if err := SelfTest(context.Background(), c, t.TempDir()); err != nil {
+				t.Errorf("%s (%s mode): %v", name, c.IOMode(), err)
+			}
+		})
+	}
+}
+
+func TestSelfTestDetectsMissingOutput(t *testing.T) {
+	// true exits 0 without writing anything, so the output file is either
+	// never created or left empty, which the self-test must report
+	for _, stdout := range []bool{false, true} {
+		args := []string{"{input}", "{output}"}
+		if stdout {
+			args = []string{"{input}"}
+		}
+		c, err := codec.NewExternalCodec(codec.ExternalDefinition{
+			Name:       "no-output",
+			Binary:     "true",
+			Stdout:     stdout,
+			Compress:   args,
+			Decompress: args,
+		})
+		if err != nil {
+			t.Fatalf("NewExternalCodec failed: %v", err)
+		}
+		if !c.IsAvailable() {
+			t.Skip("true not available")
+		}
+		err = SelfTest(context.Background(), c, t.TempDir())
+		if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("compression in %s mode produced no output", c.IOMode())) {
+			t.Errorf("%s mode: expected a missing output error, got %v", c.IOMode(), err)
+		}
+	}
+}
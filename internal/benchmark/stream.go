@@ -0,0 +1,58 @@
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/aomarai/compstat/internal/codec"
+	"github.com/aomarai/compstat/internal/util"
+)
+
+// canStream reports whether c can run without temporary files
+func canStream(c codec.Codec) bool {
+	switch c.(type) {
+	case codec.InProcessCodec, codec.StreamingCodec:
+		return true
+	}
+	return false
+}
+
+// compressStream compresses the file at input into dst, feeding the codec
+// through stdin/stdout or in-process
+func compressStream(ctx context.Context, c codec.Codec, level, threads int, params codec.Params, dict, input string, dst io.Writer) (util.Measurement, error) {
+	in, err := os.Open(input)
+	if err != nil {
+		return util.Measurement{}, err
+	}
+	defer func() {
+		if closeErr := in.Close(); closeErr != nil {
+			fmt.Printf("warning: failed to close input file: %v\n", closeErr)
+		}
+	}()
+
+	switch sc := c.(type) {
+	case codec.InProcessCodec:
+		return util.MeasureFunc(func() error {
+			return sc.Compress(dst, util.ContextReader(ctx, in), level, threads)
+		})
+	case codec.StreamingCodec:
+		return util.RunPipe(ctx, sc.Binary(), codec.StreamCompressArgs(sc, level, threads, params, dict), in, dst)
+	}
+	return util.Measurement{}, fmt.Errorf("%s does not support streaming", c.Name())
+}
+
+// decompressStream decompresses src into dst, feeding the codec through
+// stdin/stdout or in-process
+func decompressStream(ctx context.Context, c codec.Codec, threads int, params codec.Params, dict string, src io.Reader, dst io.Writer) (util.Measurement, error) {
+	switch sc := c.(type) {
+	case codec.InProcessCodec:
+		return util.MeasureFunc(func() error {
+			return sc.Decompress(dst, util.ContextReader(ctx, src), threads)
+		})
+	case codec.StreamingCodec:
+		return util.RunPipe(ctx, sc.Binary(), codec.StreamDecompressArgs(sc, threads, params, dict), src, dst)
+	}
+	return util.Measurement{}, fmt.Errorf("%s does not support streaming", c.Name())
+}
@@ -0,0 +1,70 @@
+package benchmark
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/aomarai/compstat/internal/codec"
+	"github.com/aomarai/compstat/internal/util"
+)
+
+func TestStreamingRunMatchesFileRun(t *testing.T) {
+	c := &codec.ZstdCodec{}
+	if !c.IsAvailable() {
+		t.Skip("zstd not available")
+	}
+	j := job{codec: c, level: 3, iteration: 1, compressThreads: 1, decompressThreads: 1}
+
+	sizes := make(map[bool]int64)
+	for _, stream := range []bool{false, true} {
+		runner, input := newTestRunner(t, Config{Streaming: stream})
+		j.filePath = input
+		result := runner.runSingleBenchmark(context.Background(), j)
+		if result == nil || result.Status != StatusOK || !result.Verified {
+			t.Fatalf("stream=%v: expected a verified run, got %+v", stream, result)
+		}
+		if result.UncompressedBytes != 14 || result.CompressedBytes <= 0 {
+			t.Errorf("stream=%v: unexpected sizes %d -> %d", stream, result.UncompressedBytes, result.CompressedBytes)
+		}
+		sizes[stream] = result.CompressedBytes
+
+		// File runs remove their temporary files, and streaming runs never
+		// create any
+		entries, err := os.ReadDir(runner.config.TmpDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 0 {
+			t.Errorf("stream=%v: temporary files left behind: %v", stream, entries)
+		}
+	}
+	if sizes[true] != sizes[false] {
+		t.Errorf("Streaming compressed to %d bytes, files to %d", sizes[true], sizes[false])
+	}
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	c := &codec.ZstdCodec{}
+	if !c.IsAvailable() {
+		t.Skip("zstd not available")
+	}
+	runner, input := newTestRunner(t, Config{})
+
+	var compressed bytes.Buffer
+	if _, err := compressStream(context.Background(), c, 3, 1, nil, "", input, &compressed); err != nil {
+		t.Fatalf("compressStream failed: %v", err)
+	}
+	if compressed.Len() == 0 {
+		t.Fatal("Expected compressed output")
+	}
+
+	sink := util.NewHashingWriter()
+	if _, err := decompressStream(context.Background(), c, 1, nil, "", &compressed, sink); err != nil {
+		t.Fatalf("decompressStream failed: %v", err)
+	}
+	if sink.Count() != 14 || sink.Sum() != runner.fileHashes[input] {
+		t.Errorf("Round trip gave %d bytes with hash %s, want 14 bytes with hash %s", sink.Count(), sink.Sum(), runner.fileHashes[input])
+	}
+}
@@ -0,0 +1,493 @@
+package benchmark
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aomarai/compstat/internal/codec"
+	"github.com/aomarai/compstat/internal/corpus"
+	"github.com/aomarai/compstat/internal/util"
+)
+
+// Stats summarizes a single metric across iterations
+type Stats struct {
+	N      int     `json:"n"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Median float64 `json:"median"`
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stddev"`
+	P95    float64 `json:"p95"`
+	CILow  float64 `json:"ci95_low"`
+	CIHigh float64 `json:"ci95_high"`
+}
+
+// Summary aggregates all iterations of one benchmark configuration
+type Summary struct {
+	FilePath        string `json:"file_path"`
+	Algorithm       string `json:"algorithm"`
+	Level           int    `json:"level"`
+	Params          string `json:"params"`
+	CodecVersion    string `json:"codec_version"`
+	DictionaryBytes int64  `json:"dictionary_bytes"`
+	RecordFormat    string `json:"record_format"`
+	Records         int64  `json:"records"`
+	// ChunkOverheadBytes is how many bytes each fixed-size chunk adds over
+	// compressing the whole file, or nil when the whole file has no
+	// successful run with the same configuration
+	ChunkOverheadBytes *float64 `json:"chunk_overhead_bytes,omitempty"`
+	CompressThreads    int      `json:"compress_threads"`
+	DecompressThreads  int      `json:"decompress_threads"`
+	Runs               int      `json:"runs"`
+
+	CompressionRatio      Stats `json:"compression_ratio"`
+	CompressedBytes       Stats `json:"compressed_bytes"`
+	CompressionTimeS      Stats `json:"compression_time_s"`
+	DecompressionTimeS    Stats `json:"decompression_time_s"`
+	CompressionSpeedMBs   Stats `json:"compression_speed_mbs"`
+	DecompressionSpeedMBs Stats `json:"decompression_speed_mbs"`
+	CompressionMaxRSSMB   Stats `json:"compression_max_rss_mb"`
+	DecompressionMaxRSSMB Stats `json:"decompression_max_rss_mb"`
+
+	// Record mode statistics, empty for whole-file runs
+	CompressionRecordP50Us   Stats `json:"compression_record_p50_us"`
+	CompressionRecordP99Us   Stats `json:"compression_record_p99_us"`
+	CompressionRecordsPerS   Stats `json:"compression_records_per_s"`
+	DecompressionRecordP50Us Stats `json:"decompression_record_p50_us"`
+	DecompressionRecordP99Us Stats `json:"decompression_record_p99_us"`
+	DecompressionRecordsPerS Stats `json:"decompression_records_per_s"`
+}
+
+type summaryKey struct {
+	filePath          string
+	algorithm         string
+	level             int
+	params            string
+	codecVersion      string
+	dictionaryBytes   int64
+	recordFormat      string
+	compressThreads   int
+	decompressThreads int
+}
+
+// Aggregate groups successful results by file, algorithm, level, parameters,
+// codec version, dictionary size, record format and thread counts and
+// computes statistics for each group. Decompression metrics only include runs
+// where decompression was performed.
+func Aggregate(results []Result) []Summary {
+	groups := make(map[summaryKey][]Result)
+	keys := make([]summaryKey, 0)
+	for _, res := range results {
+		if !res.Succeeded() {
+			continue
+		}
+		key := summaryKey{res.FilePath, res.Algorithm, res.Level, res.Params, res.CodecVersion, res.DictionaryBytes, res.RecordFormat, res.CompressThreads, res.DecompressThreads}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], res)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.filePath != b.filePath {
+			return a.filePath < b.filePath
+		}
+		if a.algorithm != b.algorithm {
+			return a.algorithm < b.algorithm
+		}
+		if a.level != b.level {
+			return a.level < b.level
+		}
+		if a.params != b.params {
+			return a.params < b.params
+		}
+		if a.codecVersion != b.codecVersion {
+			return a.codecVersion < b.codecVersion
+		}
+		if a.dictionaryBytes != b.dictionaryBytes {
+			return a.dictionaryBytes < b.dictionaryBytes
+		}
+		if a.recordFormat != b.recordFormat {
+			return recordFormatLess(a.recordFormat, b.recordFormat)
+		}
+		if a.compressThreads != b.compressThreads {
+			return a.compressThreads < b.compressThreads
+		}
+		return a.decompressThreads < b.decompressThreads
+	})
+
+	summaries := make([]Summary, 0, len(keys))
+	for _, key := range keys {
+		group := groups[key]
+		var ratio, compBytes, compTime, compSpeed, decompTime, decompSpeed []float64
+		var compP50, compP99, compRate, decompP50, decompP99, decompRate []float64
+		var compRSS, decompRSS []float64
+		for _, res := range group {
+			ratio = append(ratio, res.CompressionRatio)
+			compBytes = append(compBytes, float64(res.CompressedBytes))
+			compTime = append(compTime, res.CompressionTimeS)
+			compSpeed = append(compSpeed, res.CompressionSpeedMBs)
+			compRSS = append(compRSS, res.CompressionMaxRSSMB)
+			if res.DecompressionTimeS > 0 {
+				decompTime = append(decompTime, res.DecompressionTimeS)
+				decompSpeed = append(decompSpeed, res.DecompressionSpeedMBs)
+				decompRSS = append(decompRSS, res.DecompressionMaxRSSMB)
+			}
+			if res.Records == 0 {
+				continue
+			}
+			compP50 = append(compP50, res.CompressionRecordP50Us)
+			compP99 = append(compP99, res.CompressionRecordP99Us)
+			compRate = append(compRate, res.CompressionRecordsPerS)
+			if res.DecompressionTimeS > 0 {
+				decompP50 = append(decompP50, res.DecompressionRecordP50Us)
+				decompP99 = append(decompP99, res.DecompressionRecordP99Us)
+				decompRate = append(decompRate, res.DecompressionRecordsPerS)
+			}
+		}
+		summaries = append(summaries, Summary{
+			FilePath:              key.filePath,
+			Algorithm:             key.algorithm,
+			Level:                 key.level,
+			Params:                key.params,
+			CodecVersion:          key.codecVersion,
+			DictionaryBytes:       key.dictionaryBytes,
+			RecordFormat:          key.recordFormat,
+			Records:               group[0].Records,
+			CompressThreads:       key.compressThreads,
+			DecompressThreads:     key.decompressThreads,
+			Runs:                  len(group),
+			CompressionRatio:      ComputeStats(ratio),
+			CompressedBytes:       ComputeStats(compBytes),
+			CompressionTimeS:      ComputeStats(compTime),
+			DecompressionTimeS:    ComputeStats(decompTime),
+			CompressionSpeedMBs:   ComputeStats(compSpeed),
+			DecompressionSpeedMBs: ComputeStats(decompSpeed),
+			CompressionMaxRSSMB:   ComputeStats(compRSS),
+			DecompressionMaxRSSMB: ComputeStats(decompRSS),
+
+			CompressionRecordP50Us:   ComputeStats(compP50),
+			CompressionRecordP99Us:   ComputeStats(compP99),
+			CompressionRecordsPerS:   ComputeStats(compRate),
+			DecompressionRecordP50Us: ComputeStats(decompP50),
+			DecompressionRecordP99Us: ComputeStats(decompP99),
+			DecompressionRecordsPerS: ComputeStats(decompRate),
+		})
+	}
+	chunkOverhead(summaries)
+	return summaries
+}
+
+// chunkOverhead fills ChunkOverheadBytes of fixed-size chunk summaries from
+// the whole-file summary of the same configuration
+func chunkOverhead(summaries []Summary) {
+	whole := make(map[summaryKey]float64)
+	for _, s := range summaries {
+		if s.RecordFormat == "" {
+			whole[s.key("")] = s.CompressedBytes.Mean
+		}
+	}
+	for i, s := range summaries {
+		if !strings.HasPrefix(s.RecordFormat, RecordsFixed+":") || s.Records == 0 {
+			continue
+		}
+		if base, ok := whole[s.key("")]; ok {
+			overhead := (s.CompressedBytes.Mean - base) / float64(s.Records)
+			summaries[i].ChunkOverheadBytes = &overhead
+		}
+	}
+}
+
+// recordFormatLess orders record formats with whole files first and
+// fixed-size chunks by size
+func recordFormatLess(a, b string) bool {
+	sizeA, errA := ParseRecordSplit(a)
+	sizeB, errB := ParseRecordSplit(b)
+	if errA == nil && errB == nil && sizeA.Kind == RecordsFixed && sizeB.Kind == RecordsFixed {
+		return sizeA.Size < sizeB.Size
+	}
+	return a < b
+}
+
+// key returns the summary's grouping key with the given record format
+func (s Summary) key(recordFormat string) summaryKey {
+	return summaryKey{s.FilePath, s.Algorithm, s.Level, s.Params, s.CodecVersion, s.DictionaryBytes, recordFormat, s.CompressThreads, s.DecompressThreads}
+}
+
+// ComputeStats computes descriptive statistics and a 95% confidence interval
+// for the mean of values, using Student's t-distribution
+func ComputeStats(values []float64) Stats {
+	n := len(values)
+	if n == 0 {
+		return Stats{}
+	}
+
+	sorted := make([]float64, n)
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+	mean := sum / float64(n)
+
+	var stddev float64
+	if n > 1 {
+		var sq float64
+		for _, v := range sorted {
+			sq += (v - mean) * (v - mean)
+		}
+		stddev = math.Sqrt(sq / float64(n-1))
+	}
+
+	margin := 0.0
+	if n > 1 {
+		margin = tCritical95(n-1) * stddev / math.Sqrt(float64(n))
+	}
+
+	return Stats{
+		N:      n,
+		Min:    sorted[0],
+		Max:    sorted[n-1],
+		Median: percentile(sorted, 50),
+		Mean:   mean,
+		StdDev: stddev,
+		P95:    percentile(sorted, 95),
+		CILow:  mean - margin,
+		CIHigh: mean + margin,
+	}
+}
+
+// percentile returns the p-th percentile of sorted values using linear
+// interpolation between closest ranks
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	frac := rank - float64(lower)
+	return sorted[lower] + (sorted[upper]-sorted[lower])*frac
+}
+
+// tTable95 holds two-sided 95% critical values of Student's t-distribution
+// for 1 to 30 degrees of freedom
+var tTable95 = []float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+func tCritical95(df int) float64 {
+	if df <= 0 {
+		return 0
+	}
+	if df <= len(tTable95) {
+		return tTable95[df-1]
+	}
+	return 1.96
+}
+
+var summaryMetrics = []string{
+	"compression_ratio", "compressed_bytes", "compression_time_s", "decompression_time_s",
+	"compression_speed_mbs", "decompression_speed_mbs",
+	"compression_record_p50_us", "compression_record_p99_us", "compression_records_per_s",
+	"decompression_record_p50_us", "decompression_record_p99_us", "decompression_records_per_s",
+	"compression_max_rss_mb", "decompression_max_rss_mb",
+}
+
+func (s Summary) metricStats() []Stats {
+	return []Stats{
+		s.CompressionRatio, s.CompressedBytes, s.CompressionTimeS, s.DecompressionTimeS,
+		s.CompressionSpeedMBs, s.DecompressionSpeedMBs,
+		s.CompressionRecordP50Us, s.CompressionRecordP99Us, s.CompressionRecordsPerS,
+		s.DecompressionRecordP50Us, s.DecompressionRecordP99Us, s.DecompressionRecordsPerS,
+		s.CompressionMaxRSSMB, s.DecompressionMaxRSSMB,
+	}
+}
+
+// WriteSummaryCSV writes summaries to a CSV file with one row per configuration
+func WriteSummaryCSV(path string, summaries []Summary) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+
+	header := []string{"file_path", "algorithm", "level", "params", "codec_version", "dictionary_bytes", "record_format", "records", "chunk_overhead_bytes", "compress_threads", "decompress_threads", "runs"}
+	for _, metric := range summaryMetrics {
+		for _, stat := range []string{"min", "median", "mean", "stddev", "p95", "ci95_low", "ci95_high"} {
+			header = append(header, metric+"_"+stat)
+		}
+	}
+	if err := w.Write(header); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	for _, s := range summaries {
+		row := []string{
+			s.FilePath,
+			s.Algorithm,
+			strconv.Itoa(s.Level),
+			s.Params,
+			s.CodecVersion,
+			strconv.FormatInt(s.DictionaryBytes, 10),
+			s.RecordFormat,
+			strconv.FormatInt(s.Records, 10),
+			formatOverhead(s.ChunkOverheadBytes, ""),
+			strconv.Itoa(s.CompressThreads),
+			strconv.Itoa(s.DecompressThreads),
+			strconv.Itoa(s.Runs),
+		}
+		for _, st := range s.metricStats() {
+			for _, v := range []float64{st.Min, st.Median, st.Mean, st.StdDev, st.P95, st.CILow, st.CIHigh} {
+				row = append(row, fmt.Sprintf("%.4f", v))
+			}
+		}
+		if err := w.Write(row); err != nil {
+			_ = f.Close()
+			return err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// WriteSummaryJSON writes summaries to a JSON file
+func WriteSummaryJSON(path string, summaries []Summary) error {
+	data, err := json.MarshalIndent(summaries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// PrintSummaryTable writes a human-readable table of summaries to w
+func PrintSummaryTable(w io.Writer, summaries []Summary) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "FILE\tALGORITHM\tLEVEL\tPARAMS\tTHREADS\tRUNS\tRATIO\tCOMP MB/s (95% CI)\tDECOMP MB/s (95% CI)")
+	for _, s := range summaries {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%d/%d\t%d\t%.4f\t%s\t%s\n",
+			corpus.DisplayName(s.FilePath), s.Algorithm, s.Level, ParamsLabel(s.Params, s.DictionaryBytes, s.RecordFormat), s.CompressThreads, s.DecompressThreads, s.Runs,
+			s.CompressionRatio.Mean, formatMeanCI(s.CompressionSpeedMBs), formatMeanCI(s.DecompressionSpeedMBs))
+	}
+	_ = tw.Flush()
+}
+
+// ParamsLabel describes a configuration's parameters, dictionary and record
+// format for tables, or returns "-" when it has none of them
+func ParamsLabel(params string, dictionaryBytes int64, recordFormat string) string {
+	parts := make([]string, 0, 3)
+	if params != "" {
+		parts = append(parts, params)
+	}
+	if dictionaryBytes > 0 {
+		parts = append(parts, "dict="+util.FormatSize(dictionaryBytes))
+	}
+	if recordFormat != "" {
+		parts = append(parts, "records="+recordFormat)
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, ",")
+}
+
+// PrintRecordTable writes the per-record latency and throughput of record
+// mode summaries to w
+func PrintRecordTable(w io.Writer, summaries []Summary) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "FILE\tALGORITHM\tLEVEL\tPARAMS\tRECORDS\tRATIO\tCOMP p50/p99 µs\tCOMP rec/s\tDECOMP p50/p99 µs\tDECOMP rec/s")
+	for _, s := range summaries {
+		if s.RecordFormat == "" {
+			continue
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%d\t%.4f\t%s\t%s\t%s\t%s\n",
+			corpus.DisplayName(s.FilePath), s.Algorithm, s.Level, ParamsLabel(s.Params, s.DictionaryBytes, ""), s.Records,
+			s.CompressionRatio.Mean, formatLatency(s.CompressionRecordP50Us, s.CompressionRecordP99Us), formatRate(s.CompressionRecordsPerS),
+			formatLatency(s.DecompressionRecordP50Us, s.DecompressionRecordP99Us), formatRate(s.DecompressionRecordsPerS))
+	}
+	_ = tw.Flush()
+}
+
+// PrintChunkTable writes the compressed size, per-chunk overhead and chunk
+// decompression latency of each chunk size to w. Latencies of codecs that
+// run a process per chunk are marked, since they include process start-up.
+func PrintChunkTable(w io.Writer, summaries []Summary) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "FILE\tALGORITHM\tLEVEL\tPARAMS\tCHUNK\tCHUNKS\tBYTES OUT\tRATIO\tOVERHEAD B/CHUNK\tDECOMP p50/p99 µs")
+	marked := false
+	for _, s := range summaries {
+		chunk, chunks, overhead := "whole", "-", "-"
+		latency := "-"
+		if size, ok := strings.CutPrefix(s.RecordFormat, RecordsFixed+":"); ok {
+			chunk, chunks = size, strconv.FormatInt(s.Records, 10)
+			overhead = formatOverhead(s.ChunkOverheadBytes, "-")
+			latency = formatLatency(s.DecompressionRecordP50Us, s.DecompressionRecordP99Us)
+			if _, inProcess := codec.Registry[s.Algorithm].(codec.InProcessCodec); !inProcess && latency != "-" {
+				latency += " *"
+				marked = true
+			}
+		} else if s.RecordFormat != "" {
+			continue
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\t%.0f\t%.4f\t%s\t%s\n",
+			corpus.DisplayName(s.FilePath), s.Algorithm, s.Level, ParamsLabel(s.Params, s.DictionaryBytes, ""),
+			chunk, chunks, s.CompressedBytes.Mean, s.CompressionRatio.Mean, overhead, latency)
+	}
+	_ = tw.Flush()
+	if marked {
+		_, _ = fmt.Fprintln(w, "* includes process start-up: the codec runs once per chunk")
+	}
+}
+
+// formatOverhead shows a per-chunk overhead, or missing when there is no
+// whole-file baseline
+func formatOverhead(overhead *float64, missing string) string {
+	if overhead == nil {
+		return missing
+	}
+	return fmt.Sprintf("%.1f", *overhead)
+}
+
+// formatLatency shows mean p50 and p99 latencies across iterations
+func formatLatency(p50, p99 Stats) string {
+	if p50.N == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f/%.1f", p50.Mean, p99.Mean)
+}
+
+func formatRate(st Stats) string {
+	if st.N == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f", st.Mean)
+}
+
+func formatMeanCI(st Stats) string {
+	if st.N == 0 {
+		return "-"
+	}
+	if st.N == 1 {
+		return fmt.Sprintf("%.2f", st.Mean)
+	}
+	return fmt.Sprintf("%.2f ± %.2f", st.Mean, st.CIHigh-st.Mean)
+}
@@ -0,0 +1,153 @@
+package benchmark
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-3
+}
+
+func TestComputeStats(t *testing.T) {
+	st := ComputeStats([]float64{4, 1, 3, 2, 5})
+
+	if st.N != 5 {
+		t.Errorf("Expected N 5, got %d", st.N)
+	}
+	if st.Min != 1 || st.Max != 5 {
+		t.Errorf("Expected min 1 and max 5, got %f and %f", st.Min, st.Max)
+	}
+	if st.Median != 3 {
+		t.Errorf("Expected median 3, got %f", st.Median)
+	}
+	if st.Mean != 3 {
+		t.Errorf("Expected mean 3, got %f", st.Mean)
+	}
+	if !almostEqual(st.StdDev, 1.5811) {
+		t.Errorf("Expected stddev 1.5811, got %f", st.StdDev)
+	}
+	if !almostEqual(st.P95, 4.8) {
+		t.Errorf("Expected p95 4.8, got %f", st.P95)
+	}
+	// t(0.975, 4) = 2.776, margin = 2.776 * 1.5811 / sqrt(5)
+	if !almostEqual(st.CILow, 3-1.9630) || !almostEqual(st.CIHigh, 3+1.9630) {
+		t.Errorf("Unexpected confidence interval [%f, %f]", st.CILow, st.CIHigh)
+	}
+}
+
+func TestComputeStatsSingleAndEmpty(t *testing.T) {
+	st := ComputeStats([]float64{7})
+	if st.Mean != 7 || st.StdDev != 0 || st.CILow != 7 || st.CIHigh != 7 {
+		t.Errorf("Unexpected stats for single value: %+v", st)
+	}
+
+	if empty := ComputeStats(nil); empty.N != 0 {
+		t.Errorf("Expected empty stats, got %+v", empty)
+	}
+}
+
+func TestAggregateGroupsByConfiguration(t *testing.T) {
+	results := []Result{
+		{FilePath: "a.bin", Algorithm: "zstd", Level: 3, CompressThreads: 4, DecompressThreads: 4, CompressionRatio: 0.5, CompressionSpeedMBs: 100, DecompressionTimeS: 1, DecompressionSpeedMBs: 400},
+		{FilePath: "a.bin", Algorithm: "zstd", Level: 3, CompressThreads: 4, DecompressThreads: 4, CompressionRatio: 0.5, CompressionSpeedMBs: 120},
+		{FilePath: "a.bin", Algorithm: "xz", Level: 6, CompressThreads: 4, DecompressThreads: 4, CompressionRatio: 0.3, CompressionSpeedMBs: 10},
+		{FilePath: "a.bin", Algorithm: "zstd", Level: 3, CompressThreads: 1, DecompressThreads: 1, CompressionRatio: 0.5, CompressionSpeedMBs: 30},
+		{FilePath: "a.bin", Algorithm: "zstd", Level: 3, Params: "long=27", CompressThreads: 4, DecompressThreads: 4, CompressionRatio: 0.4, CompressionSpeedMBs: 90},
+	}
+
+	summaries := Aggregate(results)
+	if len(summaries) != 4 {
+		t.Fatalf("Expected 4 summaries, got %d", len(summaries))
+	}
+	if tuned := summaries[3]; tuned.Params != "long=27" || tuned.Runs != 1 {
+		t.Errorf("Expected tuned zstd runs in their own group, got %+v", tuned)
+	}
+
+	if summaries[0].Algorithm != "xz" {
+		t.Errorf("Expected summaries sorted by algorithm, got %s first", summaries[0].Algorithm)
+	}
+
+	zstd := summaries[2]
+	if zstd.CompressThreads != 4 || zstd.Runs != 2 {
+		t.Fatalf("Expected zstd with 4 threads and 2 runs, got %+v", zstd)
+	}
+	if zstd.CompressionSpeedMBs.Mean != 110 {
+		t.Errorf("Expected mean compression speed 110, got %f", zstd.CompressionSpeedMBs.Mean)
+	}
+	if zstd.DecompressionSpeedMBs.N != 1 {
+		t.Errorf("Expected runs without decompression to be excluded, got N=%d", zstd.DecompressionSpeedMBs.N)
+	}
+}
+
+func TestAggregateChunkOverhead(t *testing.T) {
+	results := []Result{
+		{FilePath: "a.bin", Algorithm: "zstd", Level: 3, CompressedBytes: 1000, CompressionRatio: 0.25},
+		{FilePath: "a.bin", Algorithm: "zstd", Level: 3, RecordFormat: "fixed:1KiB", Records: 4, CompressedBytes: 1200, CompressionRatio: 0.3},
+		{FilePath: "a.bin", Algorithm: "xz", Level: 6, RecordFormat: "fixed:1KiB", Records: 4, CompressedBytes: 900, CompressionRatio: 0.225},
+	}
+
+	summaries := Aggregate(results)
+	if len(summaries) != 3 {
+		t.Fatalf("Expected 3 summaries, got %d", len(summaries))
+	}
+	if xz := summaries[0]; xz.ChunkOverheadBytes != nil {
+		t.Errorf("Expected no overhead without a whole-file run, got %f", *xz.ChunkOverheadBytes)
+	}
+	if chunked := summaries[2]; chunked.RecordFormat != "fixed:1KiB" || chunked.ChunkOverheadBytes == nil || *chunked.ChunkOverheadBytes != 50 {
+		t.Errorf("Expected 50 bytes of overhead per chunk, got %+v", chunked)
+	}
+}
+
+func TestAggregateSortsChunkSizesNumerically(t *testing.T) {
+	results := make([]Result, 0)
+	for _, format := range []string{"fixed:256KiB", "fixed:64KiB", "", "fixed:1MiB"} {
+		results = append(results, Result{FilePath: "a.bin", Algorithm: "zstd", Level: 3, RecordFormat: format, Records: 1, CompressedBytes: 1000})
+	}
+
+	got := make([]string, 0)
+	for _, s := range Aggregate(results) {
+		got = append(got, s.RecordFormat)
+	}
+	want := []string{"", "fixed:64KiB", "fixed:256KiB", "fixed:1MiB"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Record formats sorted as %q, want %q", got, want)
+	}
+}
+
+func TestPrintChunkTableWithoutBaseline(t *testing.T) {
+	summaries := Aggregate([]Result{
+		{FilePath: "a.bin", Algorithm: "zstd", Level: 3, RecordFormat: "fixed:64KiB", Records: 4, CompressedBytes: 1200},
+	})
+
+	var buf bytes.Buffer
+	PrintChunkTable(&buf, summaries)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected a header and one row, got %q", buf.String())
+	}
+	if fields := strings.Fields(lines[1]); fields[len(fields)-2] != "-" {
+		t.Errorf("Expected no overhead without a whole-file run, got %q", lines[1])
+	}
+}
+
+func TestParamsLabel(t *testing.T) {
+	tests := []struct {
+		params  string
+		bytes   int64
+		records string
+		want    string
+	}{
+		{"", 0, "", "-"},
+		{"long=27", 0, "", "long=27"},
+		{"", 16384, "", "dict=16KiB"},
+		{"long=27", 1598, "fixed:64KiB", "long=27,dict=1598,records=fixed:64KiB"},
+	}
+	for _, tt := range tests {
+		if got := ParamsLabel(tt.params, tt.bytes, tt.records); got != tt.want {
+			t.Errorf("ParamsLabel(%q, %d, %q) = %q, want %q", tt.params, tt.bytes, tt.records, got, tt.want)
+		}
+	}
+}
@@ -1,5 +1,12 @@
 package benchmark
 
+import (
+	"time"
+
+	"github.com/aomarai/compstat/internal/codec"
+	"github.com/aomarai/compstat/internal/corpus"
+)
+
 // Result Represents a single benchmark result
 type Result struct {
 	RunID                 string  `json:"run_id"`
@@ -19,27 +26,169 @@ type Result struct {
 	DecompressionMaxRSSMB float64 `json:"decompression_max_rss_mb"`
 	Verified              bool    `json:"verified"`
 	Iteration             int     `json:"iteration"`
+
+	CompressionUserS            float64 `json:"compression_user_s"`
+	CompressionSysS             float64 `json:"compression_sys_s"`
+	CompressionVolCtxSwitches   int64   `json:"compression_voluntary_ctx_switches"`
+	CompressionInvolCtxSwitches int64   `json:"compression_involuntary_ctx_switches"`
+	CompressionMinorFaults      int64   `json:"compression_minor_faults"`
+	CompressionMajorFaults      int64   `json:"compression_major_faults"`
+
+	DecompressionUserS            float64 `json:"decompression_user_s"`
+	DecompressionSysS             float64 `json:"decompression_sys_s"`
+	DecompressionVolCtxSwitches   int64   `json:"decompression_voluntary_ctx_switches"`
+	DecompressionInvolCtxSwitches int64   `json:"decompression_involuntary_ctx_switches"`
+	DecompressionMinorFaults      int64   `json:"decompression_minor_faults"`
+	DecompressionMajorFaults      int64   `json:"decompression_major_faults"`
+
+	WarmupRuns int    `json:"warmup_runs"`
+	CacheState string `json:"cache_state"`
+
+	Params       string `json:"params"`
+	CodecVersion string `json:"codec_version"`
+
+	// DictionaryBytes is the size of the dictionary the run used, 0 without
+	// one; DictionaryTrainS is the time it took to train
+	DictionaryBytes  int64   `json:"dictionary_bytes"`
+	DictionaryTrainS float64 `json:"dictionary_train_s"`
+
+	// Record mode fields, set when the input was split into records that
+	// were compressed independently; latencies are per record
+	RecordFormat             string  `json:"record_format"`
+	Records                  int64   `json:"records"`
+	CompressionRecordP50Us   float64 `json:"compression_record_p50_us"`
+	CompressionRecordP99Us   float64 `json:"compression_record_p99_us"`
+	CompressionRecordsPerS   float64 `json:"compression_records_per_s"`
+	DecompressionRecordP50Us float64 `json:"decompression_record_p50_us"`
+	DecompressionRecordP99Us float64 `json:"decompression_record_p99_us"`
+	DecompressionRecordsPerS float64 `json:"decompression_records_per_s"`
+
+	// FileHash is the SHA-256 of the input, empty in files written before it
+	// was recorded
+	FileHash string `json:"file_hash,omitempty"`
+
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Run statuses recorded in Result.Status
+const (
+	StatusOK           = "ok"
+	StatusFailed       = "failed"
+	StatusTimeout      = "timeout"
+	StatusVerifyFailed = "verify_failed"
+)
+
+// Succeeded reports whether the run completed and, if verified, produced
+// correct output. Results from files written before statuses were recorded
+// have an empty status and count as successful.
+func (r Result) Succeeded() bool {
+	return r.Status == "" || r.Status == StatusOK
 }
 
 // Config holds benchmark configuration
 type Config struct {
-	Files               []string
-	Codecs              []string
-	CompressThreads     int
-	DecompressThreads   int
-	Iterations          int
-	TmpDir              string
-	OutputCSV           string
-	OutputJSON          string
+	// Files lists input files, directories, globs and @listfiles, expanded
+	// by corpus.ExpandPaths
+	Files []string
+	// Generate lists synthetic inputs to generate before the run; results
+	// record their specs instead of file paths
+	Generate []corpus.Spec
+	Codecs   []string
+	// Records splits each input into independently compressed records;
+	// the zero value benchmarks whole files
+	Records RecordSplit
+	// ChunkSizes sweeps independently compressed fixed-size chunks alongside
+	// whole files, where 0 also means whole files; empty means whole files only
+	ChunkSizes []int64
+	// InputMode is InputModeEach to benchmark every input on its own, or
+	// InputModeConcat to benchmark them as one tar stream
+	InputMode string
+	// Levels overrides the default level sweep per codec name; codecs
+	// missing from the map use their own Levels
+	Levels map[string][]codec.Level
+	// Params holds the parameter grid to sweep per codec name
+	Params map[string]codec.ParamGrid
+	// DictSamples lists the inputs dictionaries are trained from, with the
+	// same syntax as Files; empty means no dictionaries
+	DictSamples []string
+	// DictSizes lists the dictionary sizes to sweep, where 0 runs without a
+	// dictionary; empty means DefaultDictionarySize
+	DictSizes         []int64
+	CompressThreads   []int
+	DecompressThreads []int
+	Iterations        int
+	TmpDir            string
+	OutputCSV         string
+	OutputJSON        string
+	SummaryCSV        string
+	SummaryJSON       string
+	// CorpusSummary is the CSV or JSON file for totals across input files
+	CorpusSummary       string
 	VerifyDecompression bool
 	SkipDecompression   bool
 	Parallelism         int
+	// Streaming pipes data through codecs and keeps compressed output in
+	// memory instead of writing temporary files
+	Streaming bool
+	// WarmupRuns is the number of untimed runs before each timed run
+	WarmupRuns int
+	// CacheMode controls the page cache state of input files before each
+	// timed run: CacheModeNone, CacheModeDrop or CacheModeWarm
+	CacheMode string
+	// Resume skips runs that already have rows in OutputCSV
+	Resume bool
+	// Timeout limits each run, including warm-up; zero means no limit
+	Timeout time.Duration
 }
 
+// Input modes for Config.InputMode
+const (
+	InputModeEach   = "each"
+	InputModeConcat = "concat"
+)
+
+// Page cache modes for Config.CacheMode
+const (
+	CacheModeNone = "none"
+	CacheModeDrop = "drop"
+	CacheModeWarm = "warm"
+)
+
+// Page cache states recorded in Result.CacheState
+const (
+	CacheStateUnmanaged = "unmanaged"
+	CacheStateCold      = "cold"
+	CacheStateWarm      = "warm"
+)
+
 // Job represents a single benchmark job
 type job struct {
 	filePath  string
+	fileIndex int         // position of filePath among the inputs
+	label     string      // replaces filePath in results for generated inputs
 	codec     interface{} // Will be codec.Codec, using interface{} to avoid import cycle
 	level     int
+	params    codec.Params
+	dict      *dictionary
+	records   RecordSplit
 	iteration int
+
+	compressThreads   int
+	decompressThreads int
+}
+
+// input returns the name results record for the job's input
+func (j job) input() string {
+	if j.label != "" {
+		return j.label
+	}
+	return j.filePath
+}
+
+// ResultsFile is the layout of the JSON output: the resolved plan that
+// produced the results, followed by the results themselves
+type ResultsFile struct {
+	Plan    Plan     `json:"plan"`
+	Results []Result `json:"results"`
 }
@@ -44,8 +44,8 @@ func TestConfigStructure(t *testing.T) {
 	config := Config{
 		Files:               []string{"file1.bin", "file2.bin"},
 		Codecs:              []string{"zstd", "gzip"},
-		CompressThreads:     4,
-		DecompressThreads:   4,
+		CompressThreads:     []int{1, 2, 4},
+		DecompressThreads:   []int{4},
 		Iterations:          3,
 		TmpDir:              "/tmp",
 		OutputCSV:           "results.csv",
@@ -62,6 +62,9 @@ func TestConfigStructure(t *testing.T) {
 	if len(config.Codecs) != 2 {
 		t.Errorf("Expected 2 codecs, got %d", len(config.Codecs))
 	}
+	if len(config.CompressThreads) != 3 {
+		t.Errorf("Expected 3 compression thread counts, got %d", len(config.CompressThreads))
+	}
 	if config.Iterations != 3 {
 		t.Errorf("Expected 3 iterations, got %d", config.Iterations)
 	}
@@ -1,6 +1,7 @@
 package codec
 
 import (
+	"fmt"
 	"os/exec"
 	"strconv"
 )
@@ -23,8 +24,10 @@ func (br *BrotliCodec) Levels() []int {
 	return MakeRange(1, 11)
 }
 
+// SupportsThreading is false because the brotli CLI is single-threaded; its
+// -j flag means --rm and deletes the source file
 func (br *BrotliCodec) SupportsThreading() bool {
-	return true
+	return false
 }
 
 func (br *BrotliCodec) IsAvailable() bool {
@@ -32,10 +35,91 @@ func (br *BrotliCodec) IsAvailable() bool {
 	return err == nil
 }
 
+func (br *BrotliCodec) ValidateLevel(level int) error {
+	if level < 0 || level > 11 {
+		return fmt.Errorf("brotli does not support level %d (valid: 0..11)", level)
+	}
+	return nil
+}
+
+func (br *BrotliCodec) IOMode() IOMode {
+	return IOFile
+}
+
 func (br *BrotliCodec) CompressCommand(level, threads int, input, output string) []string {
-	return []string{"-q", strconv.Itoa(level), "-j", strconv.Itoa(threads), "-f", "-o", output, input}
+	return []string{"-q", strconv.Itoa(level), "-f", "-o", output, input}
 }
 
 func (br *BrotliCodec) DecompressCommand(threads int, input, output string) []string {
-	return []string{"-d", "-j", strconv.Itoa(threads), "-f", "-o", output, input}
+	return []string{"-d", "-f", "-o", output, input}
+}
+
+func (br *BrotliCodec) StreamCompressCommand(level, threads int) []string {
+	return []string{"-q", strconv.Itoa(level), "-c"}
+}
+
+func (br *BrotliCodec) StreamDecompressCommand(threads int) []string {
+	return []string{"-d", "-c"}
+}
+
+func (br *BrotliCodec) SupportedParams() []ParamSpec {
+	return []ParamSpec{
+		{"lgwin", "window log, 10 to 24 (--lgwin=N)"},
+		{"large_window", "large window log up to 30, not RFC 7932 compatible (--large_window=N)"},
+	}
+}
+
+func (br *BrotliCodec) CompressParamArgs(level int, params Params) []string {
+	args := make([]string, 0)
+	if v, ok := params["lgwin"]; ok {
+		args = append(args, "--lgwin="+v)
+	}
+	if v, ok := params["large_window"]; ok {
+		args = append(args, "--large_window="+v)
+	}
+	return args
+}
+
+func (br *BrotliCodec) DecompressParamArgs(params Params) []string {
+	if v, ok := params["large_window"]; ok {
+		return []string{"--large_window=" + v}
+	}
+	return nil
+}
+
+func (br *BrotliCodec) VersionArgs() []string {
+	return []string{"--version"}
+}
+
+func (br *BrotliCodec) HelpArgs() []string {
+	return []string{"--help"}
+}
+
+func (br *BrotliCodec) OptionalFlags() []string {
+	return []string{"--lgwin", "--large_window", "--dictionary"}
+}
+
+func (br *BrotliCodec) RequiredFlags(level int, params Params) []string {
+	flags := make([]string, 0)
+	for _, name := range []string{"lgwin", "large_window"} {
+		if _, ok := params[name]; ok {
+			flags = append(flags, "--"+name)
+		}
+	}
+	return flags
+}
+
+// DictionaryFlag is --dictionary, which brotli 1.1.0 and later accept
+func (br *BrotliCodec) DictionaryFlag() string {
+	return "--dictionary"
+}
+
+// TrainDictionaryArgs returns nil because the brotli CLI cannot train
+// dictionaries; it uses raw sample data as an LZ77 dictionary instead
+func (br *BrotliCodec) TrainDictionaryArgs(sampleList string, size int64, output string) []string {
+	return nil
+}
+
+func (br *BrotliCodec) DictionaryArgs(dict string) []string {
+	return []string{"--dictionary=" + dict}
 }
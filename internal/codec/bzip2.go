@@ -32,6 +32,10 @@ func (b *Bzip2Codec) IsAvailable() bool {
 	return err == nil
 }
 
+func (b *Bzip2Codec) IOMode() IOMode {
+	return IOStdout
+}
+
 func (b *Bzip2Codec) CompressCommand(level, threads int, input, output string) []string {
 	return []string{fmt.Sprintf("-%d", level), fmt.Sprintf("-p%d", threads), "-c", input}
 }
@@ -39,3 +43,47 @@ func (b *Bzip2Codec) CompressCommand(level, threads int, input, output string) [
 func (b *Bzip2Codec) DecompressCommand(threads int, input, output string) []string {
 	return []string{"-d", fmt.Sprintf("-p%d", threads), "-c", input}
 }
+
+func (b *Bzip2Codec) StreamCompressCommand(level, threads int) []string {
+	return []string{fmt.Sprintf("-%d", level), fmt.Sprintf("-p%d", threads), "-c"}
+}
+
+func (b *Bzip2Codec) StreamDecompressCommand(threads int) []string {
+	return []string{"-d", fmt.Sprintf("-p%d", threads), "-c"}
+}
+
+func (b *Bzip2Codec) SupportedParams() []ParamSpec {
+	return []ParamSpec{
+		{"block", "pbzip2 file block size in units of 100 KB (-bN)"},
+	}
+}
+
+func (b *Bzip2Codec) CompressParamArgs(level int, params Params) []string {
+	if v, ok := params["block"]; ok {
+		return []string{"-b" + v}
+	}
+	return nil
+}
+
+func (b *Bzip2Codec) DecompressParamArgs(params Params) []string {
+	return nil
+}
+
+func (b *Bzip2Codec) VersionArgs() []string {
+	return []string{"-V"}
+}
+
+func (b *Bzip2Codec) HelpArgs() []string {
+	return []string{"-h"}
+}
+
+func (b *Bzip2Codec) OptionalFlags() []string {
+	return []string{"-b#"}
+}
+
+func (b *Bzip2Codec) RequiredFlags(level int, params Params) []string {
+	if _, ok := params["block"]; ok {
+		return []string{"-b#"}
+	}
+	return nil
+}
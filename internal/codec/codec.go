@@ -1,5 +1,48 @@
 package codec
 
+import (
+	"fmt"
+	"io"
+)
+
+// IOMode describes how a codec's file commands read input and write output
+type IOMode int
+
+const (
+	// IOFile commands read the input path and write the output path themselves
+	IOFile IOMode = iota
+	// IOStdout commands read the input path and write the result to stdout
+	IOStdout
+	// IOStdinStdout commands read stdin and write stdout
+	IOStdinStdout
+	// IOInProcess codecs run inside compstat and build no commands
+	IOInProcess
+)
+
+func (m IOMode) String() string {
+	switch m {
+	case IOFile:
+		return "file"
+	case IOStdout:
+		return "stdout"
+	case IOStdinStdout:
+		return "stdin-stdout"
+	case IOInProcess:
+		return "in-process"
+	}
+	return "unknown"
+}
+
+// ParseIOMode parses the name of an IOMode as returned by its String method
+func ParseIOMode(s string) (IOMode, error) {
+	for _, m := range []IOMode{IOFile, IOStdout, IOStdinStdout, IOInProcess} {
+		if s == m.String() {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown I/O mode %q (use file, stdout or stdin-stdout)", s)
+}
+
 // Codec defines the interface for compression codecs
 type Codec interface {
 	Name() string
@@ -7,11 +50,28 @@ type Codec interface {
 	Extension() string
 	Levels() []int
 	IsAvailable() bool
+	IOMode() IOMode
 	CompressCommand(level, threads int, input, output string) []string
 	DecompressCommand(threads int, input, output string) []string
 	SupportsThreading() bool
 }
 
+// StreamingCodec is implemented by codecs whose binary can compress from stdin
+// to stdout, so data can be piped through it without temporary files
+type StreamingCodec interface {
+	Codec
+	StreamCompressCommand(level, threads int) []string
+	StreamDecompressCommand(threads int) []string
+}
+
+// InProcessCodec is implemented by codecs that compress inside the compstat
+// process using a Go library instead of executing an external binary
+type InProcessCodec interface {
+	Codec
+	Compress(dst io.Writer, src io.Reader, level, threads int) error
+	Decompress(dst io.Writer, src io.Reader, threads int) error
+}
+
 // Registry holds all available codecs
 var Registry = map[string]Codec{
 	"zstd":   &ZstdCodec{},
@@ -20,6 +80,15 @@ var Registry = map[string]Codec{
 	"lz4":    &Lz4Codec{},
 	"bzip2":  &Bzip2Codec{},
 	"brotli": &BrotliCodec{},
+
+	"go-gzip":   &GoGzipCodec{},
+	"go-flate":  &GoFlateCodec{},
+	"go-zstd":   &GoZstdCodec{},
+	"go-s2":     &GoS2Codec{},
+	"go-lz4":    &GoLz4Codec{},
+	"go-xz":     &GoXzCodec{},
+	"go-brotli": &GoBrotliCodec{},
+	"go-bzip2":  &GoBzip2Codec{},
 }
 
 // MakeRange creates a slice of integers from min to max (inclusive)
@@ -0,0 +1,31 @@
+package codec
+
+// DictionaryCodec is implemented by exec codecs that can compress and
+// decompress with a dictionary
+type DictionaryCodec interface {
+	// DictionaryFlag is the optional flag the binary needs for dictionaries
+	DictionaryFlag() string
+	// TrainDictionaryArgs returns the arguments that train a dictionary of at
+	// most size bytes from the files listed in sampleList and write it to
+	// output, or nil if the codec takes raw sample data as its dictionary
+	TrainDictionaryArgs(sampleList string, size int64, output string) []string
+	// DictionaryArgs returns the arguments that compress or decompress with
+	// the dictionary file dict
+	DictionaryArgs(dict string) []string
+}
+
+// SupportsDictionary reports whether c and its installed binary can use
+// dictionaries
+func SupportsDictionary(c Codec) bool {
+	d, ok := c.(DictionaryCodec)
+	return ok && Probe(c).Supports(d.DictionaryFlag())
+}
+
+// DictionaryArgs returns the extra arguments for compressing or
+// decompressing with dict; an empty dict means no dictionary
+func DictionaryArgs(c Codec, dict string) []string {
+	if d, ok := c.(DictionaryCodec); ok && dict != "" {
+		return d.DictionaryArgs(dict)
+	}
+	return nil
+}
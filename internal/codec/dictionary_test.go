@@ -0,0 +1,35 @@
+package codec
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDictionaryArgs(t *testing.T) {
+	tests := []struct {
+		c    Codec
+		dict string
+		want []string
+	}{
+		{&ZstdCodec{}, "samples.dict", []string{"-D", "samples.dict"}},
+		{&BrotliCodec{}, "samples.dict", []string{"--dictionary=samples.dict"}},
+		{&ZstdCodec{}, "", nil},
+		{&GzipCodec{}, "samples.dict", nil},
+	}
+	for _, tt := range tests {
+		if got := DictionaryArgs(tt.c, tt.dict); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s dictionary args for %q = %v, want %v", tt.c.Name(), tt.dict, got, tt.want)
+		}
+	}
+}
+
+func TestTrainDictionaryArgs(t *testing.T) {
+	got := (&ZstdCodec{}).TrainDictionaryArgs("samples.list", 16384, "out.dict")
+	want := []string{"--train", "--filelist", "samples.list", "--maxdict=16384", "-q", "-f", "-o", "out.dict"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("zstd training args = %v, want %v", got, want)
+	}
+	if got := (&BrotliCodec{}).TrainDictionaryArgs("samples.list", 16384, "out.dict"); got != nil {
+		t.Errorf("brotli should take raw dictionaries, got training args %v", got)
+	}
+}
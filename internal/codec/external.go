@@ -0,0 +1,245 @@
+package codec
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aomarai/compstat/internal/util"
+)
+
+// ExternalDefinition declares a codec backed by an arbitrary binary, as read
+// from a codec config file. Argument templates may use the placeholders
+// {level}, {threads}, {input} and {output}.
+type ExternalDefinition struct {
+	Name             string    `json:"name" yaml:"name" toml:"name"`
+	Binary           string    `json:"binary" yaml:"binary" toml:"binary"`
+	Extension        string    `json:"extension" yaml:"extension" toml:"extension"`
+	Levels           util.Spec `json:"levels" yaml:"levels" toml:"levels"`
+	Threads          bool      `json:"threads" yaml:"threads" toml:"threads"`
+	IO               string    `json:"io,omitempty" yaml:"io" toml:"io"`
+	Stdout           bool      `json:"stdout,omitempty" yaml:"stdout" toml:"stdout"`
+	Compress         []string  `json:"compress" yaml:"compress" toml:"compress"`
+	Decompress       []string  `json:"decompress" yaml:"decompress" toml:"decompress"`
+	StreamCompress   []string  `json:"stream_compress,omitempty" yaml:"stream_compress" toml:"stream_compress"`
+	StreamDecompress []string  `json:"stream_decompress,omitempty" yaml:"stream_decompress" toml:"stream_decompress"`
+	VersionCommand   []string  `json:"version,omitempty" yaml:"version" toml:"version"`
+}
+
+// externalConfig is the layout of a codec config file
+type externalConfig struct {
+	Codecs []ExternalDefinition `json:"codecs" yaml:"codecs" toml:"codecs"`
+}
+
+// ExternalCodec runs a binary described by an ExternalDefinition
+type ExternalCodec struct {
+	def    ExternalDefinition
+	levels []int
+	mode   IOMode
+}
+
+// externalStreamingCodec is an ExternalCodec that also declares stdin to
+// stdout commands
+type externalStreamingCodec struct {
+	*ExternalCodec
+}
+
+var placeholder = regexp.MustCompile(`\{[a-z]+\}`)
+
+// NewExternalCodec validates def and builds a codec from it
+func NewExternalCodec(def ExternalDefinition) (Codec, error) {
+	if def.Name == "" {
+		return nil, fmt.Errorf("codec definition without a name")
+	}
+	if def.Binary == "" {
+		return nil, fmt.Errorf("codec %s: binary is required", def.Name)
+	}
+	if def.Extension == "" {
+		def.Extension = "." + def.Name
+	} else if !strings.HasPrefix(def.Extension, ".") {
+		def.Extension = "." + def.Extension
+	}
+	if len(def.Compress) == 0 || len(def.Decompress) == 0 {
+		return nil, fmt.Errorf("codec %s: compress and decompress arguments are required", def.Name)
+	}
+	if (len(def.StreamCompress) == 0) != (len(def.StreamDecompress) == 0) {
+		return nil, fmt.Errorf("codec %s: stream_compress and stream_decompress must be given together", def.Name)
+	}
+
+	c := &ExternalCodec{def: def, levels: []int{0}, mode: IOFile}
+	switch {
+	case def.IO != "":
+		mode, err := ParseIOMode(def.IO)
+		if err != nil || mode == IOInProcess {
+			return nil, fmt.Errorf("codec %s: io must be file, stdout or stdin-stdout, got %q", def.Name, def.IO)
+		}
+		if def.Stdout && mode != IOStdout {
+			return nil, fmt.Errorf("codec %s: stdout: true conflicts with io: %s", def.Name, def.IO)
+		}
+		c.mode = mode
+	case def.Stdout:
+		c.mode = IOStdout
+	}
+	if def.Levels != "" {
+		levels, err := util.ParseIntList(string(def.Levels))
+		if err != nil {
+			return nil, fmt.Errorf("codec %s: levels: %w", def.Name, err)
+		}
+		c.levels = levels
+	} else if usesPlaceholder(def.Compress, "{level}") || usesPlaceholder(def.StreamCompress, "{level}") {
+		return nil, fmt.Errorf("codec %s: levels are required when arguments use {level}", def.Name)
+	}
+
+	templates := map[string][]string{
+		"compress":          def.Compress,
+		"decompress":        def.Decompress,
+		"stream_compress":   def.StreamCompress,
+		"stream_decompress": def.StreamDecompress,
+	}
+	for name, args := range templates {
+		allowed := map[string]bool{"{level}": true, "{threads}": true, "{input}": true, "{output}": true}
+		if strings.HasPrefix(name, "stream_") || c.mode == IOStdinStdout {
+			allowed["{input}"], allowed["{output}"] = false, false
+		}
+		if c.mode == IOStdout {
+			allowed["{output}"] = false
+		}
+		if name == "decompress" || name == "stream_decompress" {
+			allowed["{level}"] = false
+		}
+		for _, arg := range args {
+			for _, p := range placeholder.FindAllString(arg, -1) {
+				if !allowed[p] {
+					return nil, fmt.Errorf("codec %s: %s arguments cannot use %s", def.Name, name, p)
+				}
+			}
+		}
+	}
+	switch c.mode {
+	case IOFile:
+		if !usesPlaceholder(def.Compress, "{output}") || !usesPlaceholder(def.Decompress, "{output}") {
+			return nil, fmt.Errorf("codec %s: file mode arguments must use {output}", def.Name)
+		}
+		fallthrough
+	case IOStdout:
+		if !usesPlaceholder(def.Compress, "{input}") || !usesPlaceholder(def.Decompress, "{input}") {
+			return nil, fmt.Errorf("codec %s: %s mode arguments must use {input}", def.Name, c.mode)
+		}
+	}
+
+	if len(def.StreamCompress) > 0 {
+		return &externalStreamingCodec{c}, nil
+	}
+	return c, nil
+}
+
+// LoadExternalCodecs reads codec definitions from a YAML, TOML or JSON file
+// and adds them to the Registry. Names must not clash with existing codecs.
+func LoadExternalCodecs(path string) ([]Codec, error) {
+	var config externalConfig
+	if err := util.DecodeConfigFile(path, &config); err != nil {
+		return nil, err
+	}
+
+	codecs := make([]Codec, 0, len(config.Codecs))
+	for _, def := range config.Codecs {
+		c, err := NewExternalCodec(def)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if _, exists := Registry[c.Name()]; exists {
+			return nil, fmt.Errorf("%s: codec %s is already defined", path, c.Name())
+		}
+		codecs = append(codecs, c)
+	}
+	for _, c := range codecs {
+		Registry[c.Name()] = c
+	}
+	return codecs, nil
+}
+
+func usesPlaceholder(args []string, p string) bool {
+	for _, arg := range args {
+		if strings.Contains(arg, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func expand(args []string, level, threads int, input, output string) []string {
+	r := strings.NewReplacer(
+		"{level}", strconv.Itoa(level),
+		"{threads}", strconv.Itoa(threads),
+		"{input}", input,
+		"{output}", output,
+	)
+	expanded := make([]string, len(args))
+	for i, arg := range args {
+		expanded[i] = r.Replace(arg)
+	}
+	return expanded
+}
+
+func (e *ExternalCodec) Name() string {
+	return e.def.Name
+}
+
+func (e *ExternalCodec) Binary() string {
+	return e.def.Binary
+}
+
+func (e *ExternalCodec) Extension() string {
+	return e.def.Extension
+}
+
+func (e *ExternalCodec) Levels() []int {
+	return e.levels
+}
+
+func (e *ExternalCodec) SupportsThreading() bool {
+	return e.def.Threads
+}
+
+func (e *ExternalCodec) IsAvailable() bool {
+	_, err := exec.LookPath(e.Binary())
+	return err == nil
+}
+
+func (e *ExternalCodec) IOMode() IOMode {
+	return e.mode
+}
+
+func (e *ExternalCodec) CompressCommand(level, threads int, input, output string) []string {
+	return expand(e.def.Compress, level, threads, input, output)
+}
+
+func (e *ExternalCodec) DecompressCommand(threads int, input, output string) []string {
+	return expand(e.def.Decompress, 0, threads, input, output)
+}
+
+func (e *externalStreamingCodec) StreamCompressCommand(level, threads int) []string {
+	return expand(e.def.StreamCompress, level, threads, "", "")
+}
+
+func (e *externalStreamingCodec) StreamDecompressCommand(threads int) []string {
+	return expand(e.def.StreamDecompress, 0, threads, "", "")
+}
+
+func (e *ExternalCodec) VersionArgs() []string {
+	return e.def.VersionCommand
+}
+
+func (e *ExternalCodec) HelpArgs() []string {
+	return nil
+}
+
+func (e *ExternalCodec) OptionalFlags() []string {
+	return nil
+}
+
+func (e *ExternalCodec) RequiredFlags(level int, params Params) []string {
+	return nil
+}
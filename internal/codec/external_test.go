@@ -0,0 +1,122 @@
+package codec
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExternalCodecCommands(t *testing.T) {
+	c, err := NewExternalCodec(ExternalDefinition{
+		Name:             "lzip",
+		Binary:           "lzip",
+		Extension:        "lz",
+		Levels:           "0..9:3",
+		Threads:          true,
+		Stdout:           true,
+		Compress:         []string{"-{level}", "--threads={threads}", "-c", "{input}"},
+		Decompress:       []string{"-d", "-c", "{input}"},
+		StreamCompress:   []string{"-{level}", "-c"},
+		StreamDecompress: []string{"-d", "-c"},
+	})
+	if err != nil {
+		t.Fatalf("NewExternalCodec failed: %v", err)
+	}
+
+	if c.Extension() != ".lz" || !c.SupportsThreading() {
+		t.Errorf("Unexpected extension %q or threading %v", c.Extension(), c.SupportsThreading())
+	}
+	if !reflect.DeepEqual(c.Levels(), []int{0, 3, 6, 9}) {
+		t.Errorf("Unexpected levels %v", c.Levels())
+	}
+	if c.IOMode() != IOStdout {
+		t.Errorf("Expected stdout I/O mode, got %s", c.IOMode())
+	}
+	if got := c.CompressCommand(6, 4, "in", "out"); !reflect.DeepEqual(got, []string{"-6", "--threads=4", "-c", "in"}) {
+		t.Errorf("Unexpected compress command %v", got)
+	}
+	sc, ok := c.(StreamingCodec)
+	if !ok {
+		t.Fatal("Expected a streaming codec when stream templates are given")
+	}
+	if got := sc.StreamCompressCommand(3, 1); !reflect.DeepEqual(got, []string{"-3", "-c"}) {
+		t.Errorf("Unexpected stream compress command %v", got)
+	}
+}
+
+func TestExternalCodecValidation(t *testing.T) {
+	valid := ExternalDefinition{
+		Name:       "lzop",
+		Binary:     "lzop",
+		Levels:     "1..9",
+		Compress:   []string{"-{level}", "-o", "{output}", "{input}"},
+		Decompress: []string{"-d", "-o", "{output}", "{input}"},
+	}
+	c, err := NewExternalCodec(valid)
+	if err != nil {
+		t.Fatalf("NewExternalCodec failed: %v", err)
+	}
+	if _, ok := c.(StreamingCodec); ok {
+		t.Error("Expected no streaming support without stream templates")
+	}
+
+	tests := map[string]func(d *ExternalDefinition){
+		"no name":          func(d *ExternalDefinition) { d.Name = "" },
+		"no binary":        func(d *ExternalDefinition) { d.Binary = "" },
+		"no decompress":    func(d *ExternalDefinition) { d.Decompress = nil },
+		"no output":        func(d *ExternalDefinition) { d.Compress = []string{"-{level}", "{input}"} },
+		"unknown":          func(d *ExternalDefinition) { d.Compress = append(d.Compress, "{bogus}") },
+		"level in decomp":  func(d *ExternalDefinition) { d.Decompress = append(d.Decompress, "-{level}") },
+		"missing levels":   func(d *ExternalDefinition) { d.Levels = "" },
+		"bad levels":       func(d *ExternalDefinition) { d.Levels = "9..1" },
+		"half stream":      func(d *ExternalDefinition) { d.StreamCompress = []string{"-c"} },
+		"bad io":           func(d *ExternalDefinition) { d.IO = "in-process" },
+		"io conflict":      func(d *ExternalDefinition) { d.IO, d.Stdout = "file", true },
+		"output in stdout": func(d *ExternalDefinition) { d.IO = "stdout" },
+		"path in pipe":     func(d *ExternalDefinition) { d.IO = "stdin-stdout" },
+		"input in stream": func(d *ExternalDefinition) {
+			d.StreamCompress, d.StreamDecompress = []string{"{input}"}, []string{"-d"}
+		},
+	}
+	for name, mutate := range tests {
+		def := valid
+		mutate(&def)
+		if _, err := NewExternalCodec(def); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLoadExternalCodecs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "codecs.toml")
+	config := `
+[[codecs]]
+name = "test-lzop"
+binary = "lzop"
+levels = [1, 9]
+stdout = true
+compress = ["-{level}", "-c", "{input}"]
+decompress = ["-d", "-c", "{input}"]
+`
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	codecs, err := LoadExternalCodecs(path)
+	if err != nil {
+		t.Fatalf("LoadExternalCodecs failed: %v", err)
+	}
+	defer delete(Registry, "test-lzop")
+
+	if len(codecs) != 1 || Registry["test-lzop"] != codecs[0] {
+		t.Fatalf("Expected test-lzop to be registered, got %v", codecs)
+	}
+	if !reflect.DeepEqual(codecs[0].Levels(), []int{1, 9}) {
+		t.Errorf("Unexpected levels %v", codecs[0].Levels())
+	}
+
+	if _, err := LoadExternalCodecs(path); err == nil {
+		t.Error("Expected an error when redefining a registered codec")
+	}
+}
@@ -0,0 +1,43 @@
+package codec
+
+import (
+	"io"
+
+	"github.com/andybalholm/brotli"
+)
+
+// GoBrotliCodec compresses with andybalholm/brotli
+type GoBrotliCodec struct {
+	inProcess
+}
+
+func (br *GoBrotliCodec) Name() string {
+	return "go-brotli"
+}
+
+func (br *GoBrotliCodec) Extension() string {
+	return ".br"
+}
+
+func (br *GoBrotliCodec) Levels() []int {
+	return MakeRange(1, 11)
+}
+
+func (br *GoBrotliCodec) SupportsThreading() bool {
+	return false
+}
+
+func (br *GoBrotliCodec) Compress(dst io.Writer, src io.Reader, level, threads int) error {
+	w := brotli.NewWriterLevel(dst, level)
+	_, err := io.Copy(w, src)
+	return finish(w, err)
+}
+
+func (br *GoBrotliCodec) Decompress(dst io.Writer, src io.Reader, threads int) error {
+	_, err := io.Copy(dst, brotli.NewReader(src))
+	return err
+}
+
+func (br *GoBrotliCodec) Module() string {
+	return "github.com/andybalholm/brotli"
+}
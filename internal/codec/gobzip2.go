@@ -0,0 +1,50 @@
+package codec
+
+import (
+	"io"
+
+	"github.com/dsnet/compress/bzip2"
+)
+
+// GoBzip2Codec compresses with dsnet/compress/bzip2
+type GoBzip2Codec struct {
+	inProcess
+}
+
+func (b *GoBzip2Codec) Name() string {
+	return "go-bzip2"
+}
+
+func (b *GoBzip2Codec) Extension() string {
+	return ".bz2"
+}
+
+func (b *GoBzip2Codec) Levels() []int {
+	return MakeRange(bzip2.BestSpeed, bzip2.BestCompression)
+}
+
+func (b *GoBzip2Codec) SupportsThreading() bool {
+	return false
+}
+
+func (b *GoBzip2Codec) Compress(dst io.Writer, src io.Reader, level, threads int) error {
+	w, err := bzip2.NewWriter(dst, &bzip2.WriterConfig{Level: level})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, src)
+	return finish(w, err)
+}
+
+func (b *GoBzip2Codec) Decompress(dst io.Writer, src io.Reader, threads int) error {
+	r, err := bzip2.NewReader(src, nil)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, r)
+	return finish(r, err)
+}
+
+func (b *GoBzip2Codec) Module() string {
+	return "github.com/dsnet/compress"
+}
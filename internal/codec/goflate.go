@@ -0,0 +1,47 @@
+package codec
+
+import (
+	"io"
+
+	"github.com/klauspost/compress/flate"
+)
+
+// GoFlateCodec produces raw deflate streams with klauspost/compress/flate
+type GoFlateCodec struct {
+	inProcess
+}
+
+func (f *GoFlateCodec) Name() string {
+	return "go-flate"
+}
+
+func (f *GoFlateCodec) Extension() string {
+	return ".deflate"
+}
+
+func (f *GoFlateCodec) Levels() []int {
+	return MakeRange(1, 9)
+}
+
+func (f *GoFlateCodec) SupportsThreading() bool {
+	return false
+}
+
+func (f *GoFlateCodec) Compress(dst io.Writer, src io.Reader, level, threads int) error {
+	w, err := flate.NewWriter(dst, level)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, src)
+	return finish(w, err)
+}
+
+func (f *GoFlateCodec) Decompress(dst io.Writer, src io.Reader, threads int) error {
+	r := flate.NewReader(src)
+	_, err := io.Copy(dst, r)
+	return finish(r, err)
+}
+
+func (f *GoFlateCodec) Module() string {
+	return "github.com/klauspost/compress"
+}
@@ -0,0 +1,80 @@
+package codec
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+// GoGzipCodec compresses with the standard library compress/gzip package
+type GoGzipCodec struct {
+	inProcess
+}
+
+func (g *GoGzipCodec) Name() string {
+	return "go-gzip"
+}
+
+func (g *GoGzipCodec) Extension() string {
+	return ".gz"
+}
+
+func (g *GoGzipCodec) Levels() []int {
+	return MakeRange(1, 9)
+}
+
+func (g *GoGzipCodec) SupportsThreading() bool {
+	return false
+}
+
+func (g *GoGzipCodec) Compress(dst io.Writer, src io.Reader, level, threads int) error {
+	w, err := gzip.NewWriterLevel(dst, level)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, src)
+	return finish(w, err)
+}
+
+func (g *GoGzipCodec) Decompress(dst io.Writer, src io.Reader, threads int) error {
+	r, err := gzip.NewReader(src)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, r)
+	return finish(r, err)
+}
+
+func (g *GoGzipCodec) NewRecordCompressor(level, threads int) (RecordCoder, error) {
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, level)
+	if err != nil {
+		return nil, err
+	}
+	return recordFunc{append: func(dst, src []byte) ([]byte, error) {
+		buf.Reset()
+		w.Reset(&buf)
+		if _, err := w.Write(src); err != nil {
+			return dst, err
+		}
+		if err := w.Close(); err != nil {
+			return dst, err
+		}
+		return append(dst, buf.Bytes()...), nil
+	}}, nil
+}
+
+func (g *GoGzipCodec) NewRecordDecompressor(threads int) (RecordCoder, error) {
+	var r gzip.Reader
+	var out bytes.Buffer
+	return recordFunc{append: func(dst, src []byte) ([]byte, error) {
+		if err := r.Reset(bytes.NewReader(src)); err != nil {
+			return dst, err
+		}
+		out.Reset()
+		if _, err := out.ReadFrom(&r); err != nil {
+			return dst, err
+		}
+		return append(dst, out.Bytes()...), nil
+	}}, nil
+}
@@ -0,0 +1,62 @@
+package codec
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pierrec/lz4/v4"
+)
+
+// GoLz4Codec compresses with pierrec/lz4. Level 1 selects the fast
+// compressor and levels 2-9 the corresponding high compression levels.
+type GoLz4Codec struct {
+	inProcess
+}
+
+var goLz4Levels = []lz4.CompressionLevel{
+	lz4.Fast, lz4.Level2, lz4.Level3, lz4.Level4, lz4.Level5,
+	lz4.Level6, lz4.Level7, lz4.Level8, lz4.Level9,
+}
+
+func (l *GoLz4Codec) Name() string {
+	return "go-lz4"
+}
+
+func (l *GoLz4Codec) Extension() string {
+	return ".lz4"
+}
+
+func (l *GoLz4Codec) Levels() []int {
+	return MakeRange(1, len(goLz4Levels))
+}
+
+func (l *GoLz4Codec) SupportsThreading() bool {
+	return true
+}
+
+func (l *GoLz4Codec) Compress(dst io.Writer, src io.Reader, level, threads int) error {
+	if level < 1 || level > len(goLz4Levels) {
+		return fmt.Errorf("unsupported go-lz4 level %d", level)
+	}
+	w := lz4.NewWriter(dst)
+	if err := w.Apply(
+		lz4.CompressionLevelOption(goLz4Levels[level-1]),
+		lz4.ConcurrencyOption(threads)); err != nil {
+		return err
+	}
+	_, err := io.Copy(w, src)
+	return finish(w, err)
+}
+
+func (l *GoLz4Codec) Decompress(dst io.Writer, src io.Reader, threads int) error {
+	r := lz4.NewReader(src)
+	if err := r.Apply(lz4.ConcurrencyOption(threads)); err != nil {
+		return err
+	}
+	_, err := io.Copy(dst, r)
+	return err
+}
+
+func (l *GoLz4Codec) Module() string {
+	return "github.com/pierrec/lz4/v4"
+}
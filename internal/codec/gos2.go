@@ -0,0 +1,73 @@
+package codec
+
+import (
+	"io"
+
+	"github.com/klauspost/compress/s2"
+)
+
+// GoS2Codec compresses with klauspost/compress/s2. Level 1 is the default
+// encoder, 2 enables better compression and 3 best compression.
+type GoS2Codec struct {
+	inProcess
+}
+
+func (s *GoS2Codec) Name() string {
+	return "go-s2"
+}
+
+func (s *GoS2Codec) Extension() string {
+	return ".s2"
+}
+
+func (s *GoS2Codec) Levels() []int {
+	return MakeRange(1, 3)
+}
+
+func (s *GoS2Codec) SupportsThreading() bool {
+	return true
+}
+
+func (s *GoS2Codec) Compress(dst io.Writer, src io.Reader, level, threads int) error {
+	opts := []s2.WriterOption{s2.WriterConcurrency(threads)}
+	switch level {
+	case 2:
+		opts = append(opts, s2.WriterBetterCompression())
+	case 3:
+		opts = append(opts, s2.WriterBestCompression())
+	}
+	w := s2.NewWriter(dst, opts...)
+	_, err := io.Copy(w, src)
+	return finish(w, err)
+}
+
+func (s *GoS2Codec) Decompress(dst io.Writer, src io.Reader, threads int) error {
+	_, err := io.Copy(dst, s2.NewReader(src))
+	return err
+}
+
+// NewRecordCompressor encodes records as s2 blocks, the format s2 uses for
+// individual messages
+func (s *GoS2Codec) NewRecordCompressor(level, threads int) (RecordCoder, error) {
+	encode := s2.Encode
+	switch level {
+	case 2:
+		encode = s2.EncodeBetter
+	case 3:
+		encode = s2.EncodeBest
+	}
+	return recordFunc{append: func(dst, src []byte) ([]byte, error) {
+		return append(dst, encode(nil, src)...), nil
+	}}, nil
+}
+
+func (s *GoS2Codec) NewRecordDecompressor(threads int) (RecordCoder, error) {
+	return recordFunc{append: func(dst, src []byte) ([]byte, error) {
+		out, err := s2.Decode(nil, src)
+		return append(dst, out...), err
+	}}, nil
+}
+
+func (s *GoS2Codec) Module() string {
+	return "github.com/klauspost/compress"
+}
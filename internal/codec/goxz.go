@@ -0,0 +1,76 @@
+package codec
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ulikunitz/xz"
+	"github.com/ulikunitz/xz/lzma"
+)
+
+// GoXzCodec compresses with ulikunitz/xz. The library has no presets, so each
+// level selects the dictionary size and match finder xz(1) uses for the same
+// preset. There is no level 5: xz's presets 5 and 6 differ only in settings
+// the library does not expose.
+type GoXzCodec struct {
+	inProcess
+}
+
+// goXzSettings approximates xz's presets, which use a hash chain match
+// finder up to preset 3 and a binary tree from preset 4
+var goXzSettings = map[int]struct {
+	dictCap int
+	matcher lzma.MatchAlgorithm
+}{
+	0: {256 << 10, lzma.HashTable4},
+	1: {1 << 20, lzma.HashTable4},
+	2: {2 << 20, lzma.HashTable4},
+	3: {4 << 20, lzma.HashTable4},
+	4: {4 << 20, lzma.BinaryTree},
+	6: {8 << 20, lzma.BinaryTree},
+	7: {16 << 20, lzma.BinaryTree},
+	8: {32 << 20, lzma.BinaryTree},
+	9: {64 << 20, lzma.BinaryTree},
+}
+
+func (x *GoXzCodec) Name() string {
+	return "go-xz"
+}
+
+func (x *GoXzCodec) Extension() string {
+	return ".xz"
+}
+
+func (x *GoXzCodec) Levels() []int {
+	return []int{0, 1, 2, 3, 4, 6, 7, 8, 9}
+}
+
+func (x *GoXzCodec) SupportsThreading() bool {
+	return false
+}
+
+func (x *GoXzCodec) Compress(dst io.Writer, src io.Reader, level, threads int) error {
+	settings, ok := goXzSettings[level]
+	if !ok {
+		return fmt.Errorf("unsupported go-xz level %d", level)
+	}
+	w, err := xz.WriterConfig{DictCap: settings.dictCap, Matcher: settings.matcher}.NewWriter(dst)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, src)
+	return finish(w, err)
+}
+
+func (x *GoXzCodec) Decompress(dst io.Writer, src io.Reader, threads int) error {
+	r, err := xz.NewReader(src)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, r)
+	return err
+}
+
+func (x *GoXzCodec) Module() string {
+	return "github.com/ulikunitz/xz"
+}
@@ -0,0 +1,78 @@
+package codec
+
+import (
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// GoZstdCodec compresses with klauspost/compress/zstd. The library only
+// implements four encoder levels, which map to levels 1-4 here.
+type GoZstdCodec struct {
+	inProcess
+}
+
+func (z *GoZstdCodec) Name() string {
+	return "go-zstd"
+}
+
+func (z *GoZstdCodec) Extension() string {
+	return ".zst"
+}
+
+func (z *GoZstdCodec) Levels() []int {
+	return MakeRange(int(zstd.SpeedFastest), int(zstd.SpeedBestCompression))
+}
+
+func (z *GoZstdCodec) SupportsThreading() bool {
+	return true
+}
+
+func (z *GoZstdCodec) Compress(dst io.Writer, src io.Reader, level, threads int) error {
+	w, err := zstd.NewWriter(dst,
+		zstd.WithEncoderLevel(zstd.EncoderLevel(level)),
+		zstd.WithEncoderConcurrency(threads))
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, src)
+	return finish(w, err)
+}
+
+func (z *GoZstdCodec) Decompress(dst io.Writer, src io.Reader, threads int) error {
+	r, err := zstd.NewReader(src, zstd.WithDecoderConcurrency(threads))
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	_, err = io.Copy(dst, r)
+	return err
+}
+
+func (z *GoZstdCodec) NewRecordCompressor(level, threads int) (RecordCoder, error) {
+	enc, err := zstd.NewWriter(nil,
+		zstd.WithEncoderLevel(zstd.EncoderLevel(level)),
+		zstd.WithEncoderConcurrency(threads))
+	if err != nil {
+		return nil, err
+	}
+	return recordFunc{
+		append: func(dst, src []byte) ([]byte, error) { return enc.EncodeAll(src, dst), nil },
+		close:  enc.Close,
+	}, nil
+}
+
+func (z *GoZstdCodec) NewRecordDecompressor(threads int) (RecordCoder, error) {
+	dec, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(threads))
+	if err != nil {
+		return nil, err
+	}
+	return recordFunc{
+		append: func(dst, src []byte) ([]byte, error) { return dec.DecodeAll(src, dst) },
+		close:  func() error { dec.Close(); return nil },
+	}, nil
+}
+
+func (z *GoZstdCodec) Module() string {
+	return "github.com/klauspost/compress"
+}
@@ -33,6 +33,18 @@ func (g *GzipCodec) IsAvailable() bool {
 	return err == nil
 }
 
+// ValidateLevel accepts pigz's levels 0-9 and 11, which uses zopfli
+func (g *GzipCodec) ValidateLevel(level int) error {
+	if (level >= 0 && level <= 9) || level == 11 {
+		return nil
+	}
+	return fmt.Errorf("gzip does not support level %d (valid: 0..9, 11)", level)
+}
+
+func (g *GzipCodec) IOMode() IOMode {
+	return IOStdout
+}
+
 func (g *GzipCodec) CompressCommand(level, threads int, input, output string) []string {
 	return []string{fmt.Sprintf("-%d", level), "-p", strconv.Itoa(threads), "-c", input}
 }
@@ -40,3 +52,63 @@ func (g *GzipCodec) CompressCommand(level, threads int, input, output string) []
 func (g *GzipCodec) DecompressCommand(threads int, input, output string) []string {
 	return []string{"-d", "-p", strconv.Itoa(threads), "-c", input}
 }
+
+func (g *GzipCodec) StreamCompressCommand(level, threads int) []string {
+	return []string{fmt.Sprintf("-%d", level), "-p", strconv.Itoa(threads), "-c"}
+}
+
+func (g *GzipCodec) StreamDecompressCommand(threads int) []string {
+	return []string{"-d", "-p", strconv.Itoa(threads), "-c"}
+}
+
+func (g *GzipCodec) SupportedParams() []ParamSpec {
+	return []ParamSpec{
+		{"rsyncable", "make output rsync-friendly (--rsyncable)"},
+		{"blocksize", "compression block size in KiB (-b N)"},
+		{"independent", "compress blocks independently (-i)"},
+	}
+}
+
+func (g *GzipCodec) CompressParamArgs(level int, params Params) []string {
+	args := make([]string, 0)
+	if isTrue(params["rsyncable"]) {
+		args = append(args, "--rsyncable")
+	}
+	if v, ok := params["blocksize"]; ok {
+		args = append(args, "-b", v)
+	}
+	if isTrue(params["independent"]) {
+		args = append(args, "-i")
+	}
+	return args
+}
+
+func (g *GzipCodec) DecompressParamArgs(params Params) []string {
+	return nil
+}
+
+func (g *GzipCodec) VersionArgs() []string {
+	return []string{"--version"}
+}
+
+func (g *GzipCodec) HelpArgs() []string {
+	return []string{"--help"}
+}
+
+func (g *GzipCodec) OptionalFlags() []string {
+	return []string{"--rsyncable", "--independent", "--blocksize"}
+}
+
+func (g *GzipCodec) RequiredFlags(level int, params Params) []string {
+	flags := make([]string, 0)
+	if isTrue(params["rsyncable"]) {
+		flags = append(flags, "--rsyncable")
+	}
+	if isTrue(params["independent"]) {
+		flags = append(flags, "--independent")
+	}
+	if _, ok := params["blocksize"]; ok {
+		flags = append(flags, "--blocksize")
+	}
+	return flags
+}
@@ -0,0 +1,36 @@
+package codec
+
+import "io"
+
+// inProcess provides the exec-related Codec methods for in-process codecs,
+// which have no binary and build no command lines
+type inProcess struct{}
+
+func (inProcess) Binary() string {
+	return ""
+}
+
+func (inProcess) IsAvailable() bool {
+	return true
+}
+
+func (inProcess) IOMode() IOMode {
+	return IOInProcess
+}
+
+func (inProcess) CompressCommand(level, threads int, input, output string) []string {
+	return nil
+}
+
+func (inProcess) DecompressCommand(threads int, input, output string) []string {
+	return nil
+}
+
+// finish closes an encoder or decoder and reports the first error encountered
+func finish(c io.Closer, err error) error {
+	closeErr := c.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
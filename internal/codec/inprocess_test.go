@@ -0,0 +1,57 @@
+package codec
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestInProcessCodecsRoundTrip(t *testing.T) {
+	input := []byte(strings.Repeat("compstat in-process round trip\n", 2000))
+
+	for name, c := range Registry {
+		ip, ok := c.(InProcessCodec)
+		if !ok {
+			continue
+		}
+		levels := ip.Levels()
+		for _, level := range []int{levels[0], levels[len(levels)-1]} {
+			t.Run(fmt.Sprintf("%s/level%d", name, level), func(t *testing.T) {
+				var compressed bytes.Buffer
+				if err := ip.Compress(&compressed, bytes.NewReader(input), level, 2); err != nil {
+					t.Fatalf("Compress level %d failed: %v", level, err)
+				}
+				if compressed.Len() >= len(input) {
+					t.Errorf("Expected level %d to shrink input, got %d >= %d bytes", level, compressed.Len(), len(input))
+				}
+
+				var decompressed bytes.Buffer
+				if err := ip.Decompress(&decompressed, &compressed, 2); err != nil {
+					t.Fatalf("Decompress level %d failed: %v", level, err)
+				}
+				if !bytes.Equal(decompressed.Bytes(), input) {
+					t.Errorf("Round trip at level %d did not reproduce input", level)
+				}
+			})
+		}
+	}
+}
+
+func TestGoXzLevelsDiffer(t *testing.T) {
+	seen := make(map[string]int)
+	for _, level := range (&GoXzCodec{}).Levels() {
+		settings, ok := goXzSettings[level]
+		if !ok {
+			t.Fatalf("Level %d has no settings", level)
+		}
+		key := fmt.Sprintf("%+v", settings)
+		if other, dup := seen[key]; dup {
+			t.Errorf("Levels %d and %d have the same settings %s", other, level, key)
+		}
+		seen[key] = level
+	}
+	if len(seen) != len(goXzSettings) {
+		t.Errorf("Expected every level with settings to be advertised, got %d of %d", len(seen), len(goXzSettings))
+	}
+}
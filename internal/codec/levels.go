@@ -0,0 +1,220 @@
+package codec
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aomarai/compstat/internal/util"
+)
+
+// LevelValidator is implemented by codecs that accept levels outside the
+// default sweep returned by Levels
+type LevelValidator interface {
+	ValidateLevel(level int) error
+}
+
+// LevelSuffixer is implemented by codecs whose level lists accept suffixes
+// that select params along with the level, such as xz's "9e" for the extreme
+// variant of preset 9
+type LevelSuffixer interface {
+	LevelSuffixes() map[string]Params
+}
+
+// Level is one level to benchmark, with the params its suffix selected
+type Level struct {
+	N      int
+	Params Params
+}
+
+// PlainLevels wraps levels without suffix params
+func PlainLevels(levels []int) []Level {
+	out := make([]Level, len(levels))
+	for i, n := range levels {
+		out[i] = Level{N: n}
+	}
+	return out
+}
+
+// ValidateLevel reports whether c accepts level. Codecs without a
+// LevelValidator accept exactly the levels in their default sweep.
+func ValidateLevel(c Codec, level int) error {
+	if v, ok := c.(LevelValidator); ok {
+		return v.ValidateLevel(level)
+	}
+	if !slices.Contains(c.Levels(), level) {
+		return fmt.Errorf("%s does not support level %d", c.Name(), level)
+	}
+	return nil
+}
+
+// ParseLevels parses a level list for c, validating every level against the
+// codec's range and against the flags its installed binary was probed for.
+// Levels and ranges may end in one of the codec's level suffixes, e.g.
+// "0..9e" for xz, which selects the suffix's params for all of them.
+func ParseLevels(c Codec, spec string) ([]Level, error) {
+	levels := make([]Level, 0)
+	for _, token := range strings.Split(spec, ",") {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+		token, params := splitLevelSuffix(c, token)
+		ns, err := util.ParseIntList(token)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", c.Name(), err)
+		}
+		for _, n := range ns {
+			levels = append(levels, Level{N: n, Params: params})
+		}
+	}
+	if len(levels) == 0 {
+		return nil, fmt.Errorf("%s: empty list %q", c.Name(), spec)
+	}
+	// Plain levels come first, then each suffix's, in level order
+	sort.SliceStable(levels, func(i, j int) bool {
+		a, b := levels[i].Params.String(), levels[j].Params.String()
+		if a != b {
+			return a < b
+		}
+		return levels[i].N < levels[j].N
+	})
+	levels = slices.CompactFunc(levels, func(a, b Level) bool {
+		return a.N == b.N && a.Params.String() == b.Params.String()
+	})
+	for _, level := range levels {
+		if err := ValidateLevel(c, level.N); err != nil {
+			return nil, err
+		}
+		if missing := UnsupportedFlags(c, level.N, level.Params); len(missing) > 0 {
+			return nil, fmt.Errorf("%s level %s needs %s, which %s does not support",
+				c.Name(), FormatLevels(c, []Level{level}), strings.Join(missing, ", "), Probe(c).Path)
+		}
+	}
+	return levels, nil
+}
+
+// splitLevelSuffix strips one of c's level suffixes from a level or range,
+// accepting it on both ends of a range ("0e..9e"), and returns the params
+// it selects
+func splitLevelSuffix(c Codec, token string) (string, Params) {
+	s, ok := c.(LevelSuffixer)
+	if !ok {
+		return token, nil
+	}
+	for suffix, params := range s.LevelSuffixes() {
+		if rest, found := strings.CutSuffix(token, suffix); found {
+			return strings.Replace(rest, suffix+"..", "..", 1), params
+		}
+	}
+	return token, nil
+}
+
+// FormatLevels describes levels in the syntax ParseLevels accepts, collapsing
+// consecutive runs with the same suffix into ranges
+func FormatLevels(c Codec, levels []Level) string {
+	parts := make([]string, 0)
+	for i := 0; i < len(levels); {
+		suffix := levelSuffix(c, levels[i].Params)
+		j := i
+		for j+1 < len(levels) && levelSuffix(c, levels[j+1].Params) == suffix {
+			j++
+		}
+		ns := make([]int, 0, j-i+1)
+		for _, level := range levels[i : j+1] {
+			ns = append(ns, level.N)
+		}
+		for _, part := range strings.Split(FormatLevelRange(ns), ",") {
+			parts = append(parts, part+suffix)
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
+
+// levelSuffix returns the suffix of c that selects exactly params, or "" when
+// none does
+func levelSuffix(c Codec, params Params) string {
+	if s, ok := c.(LevelSuffixer); ok && len(params) > 0 {
+		for suffix, p := range s.LevelSuffixes() {
+			if p.String() == params.String() {
+				return suffix
+			}
+		}
+	}
+	return ""
+}
+
+// ParseLevelSpec parses a per-codec level selection such as
+// "zstd:-5..22,xz:0..9e,lz4:1,9,12". Each codec name is followed by a colon
+// and a list of levels and ranges that runs until the next codec name.
+func ParseLevelSpec(spec string) (map[string][]Level, error) {
+	parts := make(map[string][]string)
+	order := make([]string, 0)
+	current := ""
+	for _, token := range strings.Split(spec, ",") {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+		if name, rest, ok := strings.Cut(token, ":"); ok && isCodecName(name) {
+			current = name
+			token = rest
+			if _, seen := parts[current]; !seen {
+				order = append(order, current)
+			}
+		}
+		if current == "" {
+			return nil, fmt.Errorf("levels %q must start with a codec name, e.g. zstd:1..19", spec)
+		}
+		parts[current] = append(parts[current], token)
+	}
+
+	result := make(map[string][]Level, len(parts))
+	for _, name := range order {
+		c, ok := Registry[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown codec %q in levels", name)
+		}
+		levels, err := ParseLevels(c, strings.Join(parts[name], ","))
+		if err != nil {
+			return nil, err
+		}
+		result[name] = levels
+	}
+	return result, nil
+}
+
+// isCodecName reports whether s looks like a codec name rather than a level,
+// which may be negative or carry a step such as "1..9:2"
+func isCodecName(s string) bool {
+	if s == "" {
+		return false
+	}
+	first := s[0]
+	return (first >= 'a' && first <= 'z') || (first >= 'A' && first <= 'Z')
+}
+
+// FormatLevelRange describes levels compactly, collapsing consecutive runs
+// into ranges such as "1..19" or "-5..-1,1..22"
+func FormatLevelRange(levels []int) string {
+	parts := make([]string, 0)
+	for i := 0; i < len(levels); {
+		j := i
+		for j+1 < len(levels) && levels[j+1] == levels[j]+1 {
+			j++
+		}
+		switch {
+		case j == i:
+			parts = append(parts, strconv.Itoa(levels[i]))
+		case j == i+1:
+			parts = append(parts, strconv.Itoa(levels[i]), strconv.Itoa(levels[j]))
+		default:
+			parts = append(parts, strconv.Itoa(levels[i])+".."+strconv.Itoa(levels[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
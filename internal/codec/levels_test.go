@@ -0,0 +1,136 @@
+package codec
+
+import (
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLevelSpec(t *testing.T) {
+	got, err := ParseLevelSpec("zstd:-5..-4,20..22, xz:0..1e,9, lz4:1,9,12")
+	if err != nil {
+		t.Fatalf("ParseLevelSpec failed: %v", err)
+	}
+	extreme := Params{"extreme": "true"}
+	want := map[string][]Level{
+		"zstd": PlainLevels([]int{-5, -4, 20, 21, 22}),
+		"xz":   {{N: 9}, {N: 0, Params: extreme}, {N: 1, Params: extreme}},
+		"lz4":  PlainLevels([]int{1, 9, 12}),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseLevelSpec = %v, want %v", got, want)
+	}
+}
+
+func TestParseLevelSpecErrors(t *testing.T) {
+	for _, spec := range []string{
+		"1..9",
+		"nosuch:1",
+		"zstd:0",
+		"zstd:23",
+		"lz4:13",
+		"xz:10",
+		"xz:10e",
+		"zstd:9e",
+		"bzip2:0",
+		"gzip:10",
+		"zstd:a..b",
+	} {
+		if _, err := ParseLevelSpec(spec); err == nil {
+			t.Errorf("ParseLevelSpec(%q) succeeded, want error", spec)
+		}
+	}
+}
+
+func TestLevelCommands(t *testing.T) {
+	tests := []struct {
+		c     StreamingCodec
+		level int
+		want  []string
+	}{
+		{&ZstdCodec{}, -5, []string{"--fast=5", "-T1", "-q", "-c"}},
+		{&ZstdCodec{}, 22, []string{"--ultra", "-22", "-T1", "-q", "-c"}},
+		{&Lz4Codec{}, -3, []string{"--fast=3", "-c"}},
+		{&Lz4Codec{}, 12, []string{"-12", "-c"}},
+		{&XzCodec{}, 6, []string{"-6", "-T1", "-c"}},
+		{&XzCodec{}, 9, []string{"-9", "-e", "-T1", "-c"}},
+	}
+	for _, tt := range tests {
+		if got := tt.c.StreamCompressCommand(tt.level, 1); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s level %d: got %v, want %v", tt.c.Name(), tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestParseLevelsChecksProbedFlags(t *testing.T) {
+	if _, err := exec.LookPath("echo"); err != nil {
+		t.Skip("echo not available")
+	}
+	base, err := NewExternalCodec(ExternalDefinition{
+		Name:       "levels-echo",
+		Binary:     "echo",
+		Levels:     "1..22",
+		IO:         "stdout",
+		Compress:   []string{"-{level}", "{input}"},
+		Decompress: []string{"{input}"},
+	})
+	if err != nil {
+		t.Fatalf("NewExternalCodec failed: %v", err)
+	}
+	// The "help" output lacks --ultra, which levels above 19 need
+	c := &echoCodec{ExternalCodec: base.(*ExternalCodec), help: []string{"usage: --long"}}
+	defer func() {
+		probeMux.Lock()
+		delete(probeCache, c.Name())
+		probeMux.Unlock()
+	}()
+
+	if _, err := ParseLevels(c, "1..19"); err != nil {
+		t.Errorf("ParseLevels(1..19) failed: %v", err)
+	}
+	_, err = ParseLevels(c, "18..22")
+	if err == nil || !strings.Contains(err.Error(), "--ultra") {
+		t.Errorf("Expected an error naming --ultra, got %v", err)
+	}
+}
+
+func TestFormatLevels(t *testing.T) {
+	xz := &XzCodec{}
+	for spec, want := range map[string]string{
+		"0..9e":       "0..9e",
+		"0e..9e":      "0..9e",
+		"9e,0..3":     "0..3,9e",
+		"0..8,0..8e":  "0..8,0..8e",
+		"1,3,5e,6e,9": "1,3,9,5e,6e",
+	} {
+		levels, err := ParseLevels(xz, spec)
+		if err != nil {
+			t.Errorf("ParseLevels(%q) failed: %v", spec, err)
+			continue
+		}
+		if got := FormatLevels(xz, levels); got != want {
+			t.Errorf("FormatLevels(ParseLevels(%q)) = %q, want %q", spec, got, want)
+		}
+	}
+	if got := FormatLevels(&ZstdCodec{}, PlainLevels([]int{-1, 1, 2, 3})); got != "-1,1..3" {
+		t.Errorf("FormatLevels(zstd) = %q, want -1,1..3", got)
+	}
+}
+
+func TestFormatLevelRange(t *testing.T) {
+	tests := []struct {
+		levels []int
+		want   string
+	}{
+		{MakeRange(1, 19), "1..19"},
+		{[]int{-5, -4, 1, 3, 4, 5, 22}, "-5,-4,1,3..5,22"},
+		{[]int{-3, -2, -1, 0, 1}, "-3..1"},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := FormatLevelRange(tt.levels); got != tt.want {
+			t.Errorf("FormatLevelRange(%v) = %q, want %q", tt.levels, got, tt.want)
+		}
+	}
+}
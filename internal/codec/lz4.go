@@ -32,10 +32,86 @@ func (l *Lz4Codec) IsAvailable() bool {
 	return err == nil
 }
 
+// ValidateLevel accepts levels 1-12, where 10 and above select LZ4HC's
+// slowest modes, and negative levels, which select --fast=N
+func (l *Lz4Codec) ValidateLevel(level int) error {
+	if level == 0 || level > 12 {
+		return fmt.Errorf("lz4 does not support level %d (valid: negative fast levels, 1..12)", level)
+	}
+	return nil
+}
+
+func (l *Lz4Codec) levelArg(level int) string {
+	if level < 0 {
+		return fmt.Sprintf("--fast=%d", -level)
+	}
+	return fmt.Sprintf("-%d", level)
+}
+
+func (l *Lz4Codec) IOMode() IOMode {
+	return IOFile
+}
+
 func (l *Lz4Codec) CompressCommand(level, threads int, input, output string) []string {
-	return []string{fmt.Sprintf("-%d", level), input, output}
+	return []string{l.levelArg(level), "-f", input, output}
 }
 
 func (l *Lz4Codec) DecompressCommand(threads int, input, output string) []string {
-	return []string{"-d", input, output}
+	return []string{"-d", "-f", input, output}
+}
+
+func (l *Lz4Codec) StreamCompressCommand(level, threads int) []string {
+	return []string{l.levelArg(level), "-c"}
+}
+
+func (l *Lz4Codec) StreamDecompressCommand(threads int) []string {
+	return []string{"-d", "-c"}
+}
+
+func (l *Lz4Codec) SupportedParams() []ParamSpec {
+	return []ParamSpec{
+		{"block", "block size ID, 4 (64 KB) to 7 (4 MB) (-B N)"},
+		{"dependent", "make blocks depend on previous ones (-BD)"},
+	}
+}
+
+func (l *Lz4Codec) CompressParamArgs(level int, params Params) []string {
+	args := make([]string, 0)
+	if v, ok := params["block"]; ok {
+		args = append(args, "-B"+v)
+	}
+	if isTrue(params["dependent"]) {
+		args = append(args, "-BD")
+	}
+	return args
+}
+
+func (l *Lz4Codec) DecompressParamArgs(params Params) []string {
+	return nil
+}
+
+func (l *Lz4Codec) VersionArgs() []string {
+	return []string{"-V"}
+}
+
+func (l *Lz4Codec) HelpArgs() []string {
+	return []string{"-H"}
+}
+
+func (l *Lz4Codec) OptionalFlags() []string {
+	return []string{"--fast", "-B#", "-BD"}
+}
+
+func (l *Lz4Codec) RequiredFlags(level int, params Params) []string {
+	flags := make([]string, 0)
+	if level < 0 {
+		flags = append(flags, "--fast")
+	}
+	if _, ok := params["block"]; ok {
+		flags = append(flags, "-B#")
+	}
+	if isTrue(params["dependent"]) {
+		flags = append(flags, "-BD")
+	}
+	return flags
 }
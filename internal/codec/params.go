@@ -0,0 +1,242 @@
+package codec
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Unset is the parameter value that leaves a parameter at the codec default,
+// so a grid can include the untuned configuration
+const Unset = "-"
+
+// Params holds codec-specific settings beyond the level, keyed by name
+type Params map[string]string
+
+// String returns the parameters as "name=value" pairs sorted by name, which is
+// the form recorded in the params column
+func (p Params) String() string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + "=" + p[name]
+	}
+	return strings.Join(pairs, ",")
+}
+
+// ParamSpec describes one parameter accepted by a Tunable codec
+type ParamSpec struct {
+	Name        string
+	Description string
+}
+
+// Tunable is implemented by codecs that accept parameters beyond the level.
+// The returned arguments are placed before the codec's regular command line,
+// unless the codec is a ParamCommander.
+type Tunable interface {
+	Codec
+	SupportedParams() []ParamSpec
+	CompressParamArgs(level int, params Params) []string
+	DecompressParamArgs(params Params) []string
+}
+
+// ParamCommander is implemented by Tunable codecs whose parameter arguments
+// cannot simply precede the command line. xz, for one, discards a custom
+// filter chain when a preset follows it, so its parameters replace the
+// preset instead.
+type ParamCommander interface {
+	Tunable
+	CompressParamCommand(level, threads int, params Params, input, output string) []string
+	StreamCompressParamCommand(level, threads int, params Params) []string
+}
+
+// CompressArgs returns the full argument list of a compression run:
+// dictionary and parameter arguments followed by the codec's command line
+func CompressArgs(c Codec, level, threads int, params Params, dict, input, output string) []string {
+	args := DictionaryArgs(c, dict)
+	if pc, ok := c.(ParamCommander); ok && len(params) > 0 {
+		return append(args, pc.CompressParamCommand(level, threads, params, input, output)...)
+	}
+	args = append(args, CompressParamArgs(c, level, params)...)
+	return append(args, c.CompressCommand(level, threads, input, output)...)
+}
+
+// DecompressArgs returns the full argument list of a decompression run
+func DecompressArgs(c Codec, threads int, params Params, dict, input, output string) []string {
+	args := append(DictionaryArgs(c, dict), DecompressParamArgs(c, params)...)
+	return append(args, c.DecompressCommand(threads, input, output)...)
+}
+
+// StreamCompressArgs returns the full argument list of a compression run
+// from stdin to stdout
+func StreamCompressArgs(c StreamingCodec, level, threads int, params Params, dict string) []string {
+	args := DictionaryArgs(c, dict)
+	if pc, ok := c.(ParamCommander); ok && len(params) > 0 {
+		return append(args, pc.StreamCompressParamCommand(level, threads, params)...)
+	}
+	args = append(args, CompressParamArgs(c, level, params)...)
+	return append(args, c.StreamCompressCommand(level, threads)...)
+}
+
+// StreamDecompressArgs returns the full argument list of a decompression run
+// from stdin to stdout
+func StreamDecompressArgs(c StreamingCodec, threads int, params Params, dict string) []string {
+	args := append(DictionaryArgs(c, dict), DecompressParamArgs(c, params)...)
+	return append(args, c.StreamDecompressCommand(threads)...)
+}
+
+// CompressParamArgs returns the extra compression arguments for params
+func CompressParamArgs(c Codec, level int, params Params) []string {
+	if t, ok := c.(Tunable); ok && len(params) > 0 {
+		return t.CompressParamArgs(level, params)
+	}
+	return nil
+}
+
+// DecompressParamArgs returns the extra decompression arguments for params
+func DecompressParamArgs(c Codec, params Params) []string {
+	if t, ok := c.(Tunable); ok && len(params) > 0 {
+		return t.DecompressParamArgs(params)
+	}
+	return nil
+}
+
+// ParamGrid holds the values to sweep for each parameter of one codec
+type ParamGrid map[string][]string
+
+// ParseParamSpec parses one "codec:name=v1,v2" parameter declaration and adds
+// it to grids, keyed by codec name
+func ParseParamSpec(grids map[string]ParamGrid, spec string) error {
+	name, rest, ok := strings.Cut(spec, ":")
+	if !ok {
+		return fmt.Errorf("parameter %q must look like codec:name=value[,value...]", spec)
+	}
+	c, ok := Registry[strings.TrimSpace(name)]
+	if !ok {
+		return fmt.Errorf("unknown codec %q in parameter %q", name, spec)
+	}
+	param, values, ok := strings.Cut(rest, "=")
+	param = strings.TrimSpace(param)
+	if !ok || param == "" {
+		return fmt.Errorf("parameter %q must look like codec:name=value[,value...]", spec)
+	}
+	if !supportsParam(c, param) {
+		return fmt.Errorf("%s does not support parameter %q%s", c.Name(), param, supportedParamsHint(c))
+	}
+
+	list := make([]string, 0)
+	for _, v := range strings.Split(values, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			return fmt.Errorf("empty value in parameter %q", spec)
+		}
+		list = append(list, v)
+	}
+
+	if grids[c.Name()] == nil {
+		grids[c.Name()] = make(ParamGrid)
+	}
+	grids[c.Name()][param] = append(grids[c.Name()][param], list...)
+	return nil
+}
+
+// CombinationsWith expands the grid like Combinations, with the values in
+// fixed replacing the grid's. Combinations that become identical are
+// returned once.
+func (g ParamGrid) CombinationsWith(fixed Params) []Params {
+	combos := g.Combinations()
+	if len(fixed) == 0 {
+		return combos
+	}
+	seen := make(map[string]bool, len(combos))
+	out := make([]Params, 0, len(combos))
+	for _, combo := range combos {
+		for k, v := range fixed {
+			combo[k] = v
+		}
+		if key := combo.String(); !seen[key] {
+			seen[key] = true
+			out = append(out, combo)
+		}
+	}
+	return out
+}
+
+// Combinations expands the grid into every combination of parameter values.
+// An empty grid yields a single empty Params.
+func (g ParamGrid) Combinations() []Params {
+	names := make([]string, 0, len(g))
+	for name := range g {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	combos := []Params{{}}
+	for _, name := range names {
+		next := make([]Params, 0, len(combos)*len(g[name]))
+		for _, combo := range combos {
+			for _, value := range g[name] {
+				p := make(Params, len(combo)+1)
+				for k, v := range combo {
+					p[k] = v
+				}
+				if value != Unset {
+					p[name] = value
+				}
+				next = append(next, p)
+			}
+		}
+		combos = next
+	}
+	return combos
+}
+
+func supportsParam(c Codec, name string) bool {
+	t, ok := c.(Tunable)
+	if !ok {
+		return false
+	}
+	for _, spec := range t.SupportedParams() {
+		if spec.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func supportedParamsHint(c Codec) string {
+	t, ok := c.(Tunable)
+	if !ok {
+		return " (it has no tunable parameters)"
+	}
+	names := make([]string, 0)
+	for _, spec := range t.SupportedParams() {
+		names = append(names, spec.Name)
+	}
+	return " (supported: " + strings.Join(names, ", ") + ")"
+}
+
+// isTrue reports whether a boolean parameter value enables the option
+func isTrue(value string) bool {
+	switch strings.ToLower(value) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
+
+// subOptions joins the params listed in names, in that order, into a
+// "name=value,..." option string as used by zstd's --zstd and xz's --lzma2
+func subOptions(params Params, names ...string) []string {
+	opts := make([]string, 0)
+	for _, name := range names {
+		if v, ok := params[name]; ok {
+			opts = append(opts, name+"="+v)
+		}
+	}
+	return opts
+}
@@ -0,0 +1,162 @@
+package codec
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParamGridCombinations(t *testing.T) {
+	grids := make(map[string]ParamGrid)
+	for _, spec := range []string{"zstd:long=-,27", "zstd:strat=5,9"} {
+		if err := ParseParamSpec(grids, spec); err != nil {
+			t.Fatalf("ParseParamSpec(%q) failed: %v", spec, err)
+		}
+	}
+
+	var got []string
+	for _, p := range grids["zstd"].Combinations() {
+		got = append(got, p.String())
+	}
+	want := []string{"strat=5", "strat=9", "long=27,strat=5", "long=27,strat=9"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Combinations = %v, want %v", got, want)
+	}
+
+	if combos := ParamGrid(nil).Combinations(); len(combos) != 1 || len(combos[0]) != 0 {
+		t.Errorf("empty grid Combinations = %v, want one empty Params", combos)
+	}
+}
+
+func TestParamGridCombinationsWith(t *testing.T) {
+	grid := ParamGrid{"extreme": {"false", "true"}, "dict": {"-", "64MiB"}}
+
+	var got []string
+	for _, p := range grid.CombinationsWith(Params{"extreme": "true"}) {
+		got = append(got, p.String())
+	}
+	want := []string{"extreme=true", "dict=64MiB,extreme=true"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CombinationsWith = %v, want %v", got, want)
+	}
+
+	if combos := grid.CombinationsWith(nil); len(combos) != 4 {
+		t.Errorf("CombinationsWith(nil) = %v, want the full grid", combos)
+	}
+}
+
+func TestParseParamSpecErrors(t *testing.T) {
+	for _, spec := range []string{
+		"long=27",
+		"nosuch:long=27",
+		"zstd:nosuch=1",
+		"zstd:long=",
+		"go-zstd:long=27",
+	} {
+		if err := ParseParamSpec(make(map[string]ParamGrid), spec); err == nil {
+			t.Errorf("ParseParamSpec(%q) succeeded, want error", spec)
+		}
+	}
+}
+
+func TestParamArgs(t *testing.T) {
+	tests := []struct {
+		c      Codec
+		level  int
+		params Params
+		comp   []string
+		decomp []string
+	}{
+		{&ZstdCodec{}, 19, Params{"long": "27", "wlog": "24", "strat": "9"},
+			[]string{"--long=27", "--zstd=wlog=24,strat=9"}, []string{"--long=27"}},
+		{&XzCodec{}, 6, Params{"dict": "64MiB", "mf": "bt4", "extreme": "true"},
+			[]string{"--lzma2=preset=6e,dict=64MiB,mf=bt4"}, nil},
+		{&GzipCodec{}, 6, Params{"rsyncable": "true", "independent": "false"},
+			[]string{"--rsyncable"}, nil},
+		{&Lz4Codec{}, 1, Params{"block": "7", "dependent": "1"},
+			[]string{"-B7", "-BD"}, nil},
+		{&BrotliCodec{}, 11, Params{"lgwin": "24"},
+			[]string{"--lgwin=24"}, nil},
+		{&ZstdCodec{}, 3, nil, nil, nil},
+	}
+	for _, tt := range tests {
+		if got := CompressParamArgs(tt.c, tt.level, tt.params); !reflect.DeepEqual(got, tt.comp) {
+			t.Errorf("%s compress args for %v = %v, want %v", tt.c.Name(), tt.params, got, tt.comp)
+		}
+		if got := DecompressParamArgs(tt.c, tt.params); !reflect.DeepEqual(got, tt.decomp) {
+			t.Errorf("%s decompress args for %v = %v, want %v", tt.c.Name(), tt.params, got, tt.decomp)
+		}
+	}
+}
+
+func TestCommandArgsOrder(t *testing.T) {
+	xz := &XzCodec{}
+	zstd := &ZstdCodec{}
+	tests := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{"xz", CompressArgs(xz, 6, 2, Params{"dict": "64KiB"}, "", "in", "out"),
+			[]string{"--lzma2=preset=6,dict=64KiB", "-T2", "-c", "in"}},
+		{"xz level 9", CompressArgs(xz, 9, 1, Params{"mf": "bt4"}, "", "in", "out"),
+			[]string{"--lzma2=preset=9e,mf=bt4", "-T1", "-c", "in"}},
+		{"xz level 9 not extreme", CompressArgs(xz, 9, 1, Params{"extreme": "false"}, "", "in", "out"),
+			[]string{"-9", "-T1", "-c", "in"}},
+		{"xz extreme only", CompressArgs(xz, 6, 1, Params{"extreme": "true"}, "", "in", "out"),
+			[]string{"-6", "-e", "-T1", "-c", "in"}},
+		{"xz without params", CompressArgs(xz, 6, 1, nil, "", "in", "out"),
+			[]string{"-6", "-T1", "-c", "in"}},
+		{"xz stream", StreamCompressArgs(xz, 6, 1, Params{"dict": "64KiB"}, ""),
+			[]string{"--lzma2=preset=6,dict=64KiB", "-T1", "-c"}},
+		{"zstd", CompressArgs(zstd, 19, 1, Params{"long": "27"}, "d.dict", "in", "out"),
+			append([]string{"-D", "d.dict", "--long=27"}, zstd.CompressCommand(19, 1, "in", "out")...)},
+		{"zstd decompress", DecompressArgs(zstd, 1, Params{"long": "27"}, "", "in", "out"),
+			append([]string{"--long=27"}, zstd.DecompressCommand(1, "in", "out")...)},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s: args = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestXzParamsTakeEffect(t *testing.T) {
+	xz := &XzCodec{}
+	if !xz.IsAvailable() {
+		t.Skip("xz not available")
+	}
+	dir := t.TempDir()
+	input := filepath.Join(dir, "in")
+	if err := os.WriteFile(input, bytes.Repeat([]byte("compstat "), 4096), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command("xz", CompressArgs(xz, 6, 1, Params{"dict": "64KiB"}, "", input, "")...).Output()
+	if err != nil {
+		t.Fatalf("xz failed: %v", err)
+	}
+	compressed := filepath.Join(dir, "in.xz")
+	if err := os.WriteFile(compressed, out, 0644); err != nil {
+		t.Fatal(err)
+	}
+	// The robot listing shows the filter chain of each block
+	list, err := exec.Command("xz", "--robot", "--list", "-vv", compressed).Output()
+	if err != nil {
+		t.Fatalf("xz --list failed: %v", err)
+	}
+	if !strings.Contains(string(list), "dict=64KiB") {
+		t.Errorf("Expected a 64KiB dictionary, got:\n%s", list)
+	}
+}
+
+func TestZstdLevel19NoForcedLong(t *testing.T) {
+	for _, arg := range (&ZstdCodec{}).CompressCommand(19, 1, "in", "out") {
+		if arg == "--long=31" || arg == "--ultra" {
+			t.Errorf("level 19 command contains %s", arg)
+		}
+	}
+}
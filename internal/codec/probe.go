@@ -0,0 +1,155 @@
+package codec
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Capabilities records what probing learned about a codec
+type Capabilities struct {
+	// Path is the resolved binary path, empty for in-process codecs
+	Path string `json:"path,omitempty"`
+	// Version is the detected binary or library version, empty if unknown
+	Version string `json:"version,omitempty"`
+	// Flags maps each optional flag to whether the binary supports it; nil
+	// when support could not be detected
+	Flags map[string]bool `json:"flags,omitempty"`
+}
+
+// Supports reports whether the probed binary accepts flag. Flags are assumed
+// to be supported when detection was not possible.
+func (c Capabilities) Supports(flag string) bool {
+	supported, known := c.Flags[flag]
+	return supported || !known
+}
+
+// Probed is implemented by exec codecs that can report the version of their
+// binary and which optional flags it accepts
+type Probed interface {
+	VersionArgs() []string
+	HelpArgs() []string
+	OptionalFlags() []string
+	// RequiredFlags lists the optional flags a configuration relies on
+	RequiredFlags(level int, params Params) []string
+}
+
+// LibraryCodec is implemented by in-process codecs built on a third-party Go
+// module; codecs without it use the standard library
+type LibraryCodec interface {
+	Module() string
+}
+
+// probeTimeout bounds each version or help command
+const probeTimeout = 5 * time.Second
+
+var versionPattern = regexp.MustCompile(`\d+\.\d+(\.\d+)?`)
+
+var (
+	probeCache = make(map[string]Capabilities)
+	probeMux   sync.Mutex
+)
+
+// Probe detects the version and supported flags of c. Results are cached per
+// codec name for the lifetime of the process.
+func Probe(c Codec) Capabilities {
+	probeMux.Lock()
+	defer probeMux.Unlock()
+	if caps, ok := probeCache[c.Name()]; ok {
+		return caps
+	}
+	caps := probe(c)
+	probeCache[c.Name()] = caps
+	return caps
+}
+
+func probe(c Codec) Capabilities {
+	if c.IOMode() == IOInProcess {
+		return Capabilities{Version: libraryVersion(c)}
+	}
+
+	var caps Capabilities
+	path, err := exec.LookPath(c.Binary())
+	if err != nil {
+		return caps
+	}
+	caps.Path = path
+
+	p, ok := c.(Probed)
+	if !ok {
+		return caps
+	}
+	if args := p.VersionArgs(); len(args) > 0 {
+		caps.Version = ParseVersion(probeOutput(path, args))
+	}
+	if args := p.HelpArgs(); len(args) > 0 {
+		if help := probeOutput(path, args); help != "" {
+			caps.Flags = make(map[string]bool)
+			for _, flag := range p.OptionalFlags() {
+				caps.Flags[flag] = strings.Contains(help, flag)
+			}
+		}
+	}
+	return caps
+}
+
+// probeOutput runs a version or help command and returns its combined output.
+// The exit status is ignored because some binaries exit non-zero after
+// printing help.
+func probeOutput(path string, args []string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	_ = cmd.Run()
+	return out.String()
+}
+
+// ParseVersion extracts the first dotted version number from a version
+// banner, such as "1.5.6" from "*** Zstandard CLI (64-bit) v1.5.6 ***"
+func ParseVersion(output string) string {
+	return versionPattern.FindString(output)
+}
+
+// libraryVersion reports the Go module and version an in-process codec was
+// built with
+func libraryVersion(c Codec) string {
+	lib, ok := c.(LibraryCodec)
+	if !ok {
+		return runtime.Version()
+	}
+	module := lib.Module()
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if dep.Path == module {
+				return module + "@" + dep.Version
+			}
+		}
+	}
+	return module
+}
+
+// UnsupportedFlags returns the optional flags a configuration needs that the
+// probed binary lacks
+func UnsupportedFlags(c Codec, level int, params Params) []string {
+	p, ok := c.(Probed)
+	if !ok {
+		return nil
+	}
+	caps := Probe(c)
+	missing := make([]string, 0)
+	for _, flag := range p.RequiredFlags(level, params) {
+		if !caps.Supports(flag) {
+			missing = append(missing, flag)
+		}
+	}
+	return missing
+}
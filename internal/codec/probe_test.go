@@ -0,0 +1,136 @@
+package codec
+
+import (
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := map[string]string{
+		"*** Zstandard CLI (64-bit) v1.5.6, by Yann Collet ***":             "1.5.6",
+		"xz (XZ Utils) 5.4.6\nliblzma 5.4.6":                                "5.4.6",
+		"*** LZ4 command line interface 64-bits v1.9.4, by Yann Collet ***": "1.9.4",
+		"pigz 2.8":                 "2.8",
+		"brotli 1.1.0":             "1.1.0",
+		"no version number here":   "",
+		"Parallel BZIP2 v1.1.13 -": "1.1.13",
+	}
+	for banner, want := range tests {
+		if got := ParseVersion(banner); got != want {
+			t.Errorf("ParseVersion(%q) = %q, want %q", banner, got, want)
+		}
+	}
+}
+
+func TestCapabilitiesSupports(t *testing.T) {
+	caps := Capabilities{Flags: map[string]bool{"--ultra": true, "--long": false}}
+	if !caps.Supports("--ultra") || caps.Supports("--long") {
+		t.Errorf("Unexpected support for detected flags: %+v", caps)
+	}
+	if !caps.Supports("--unknown") {
+		t.Error("Flags that were not probed should be assumed supported")
+	}
+}
+
+// echoCodec probes with echo, so its "help" lists exactly the given flags
+type echoCodec struct {
+	*ExternalCodec
+	help []string
+}
+
+func (e *echoCodec) HelpArgs() []string      { return e.help }
+func (e *echoCodec) OptionalFlags() []string { return []string{"--ultra", "--long"} }
+func (e *echoCodec) RequiredFlags(level int, params Params) []string {
+	if level > 19 {
+		return []string{"--ultra"}
+	}
+	if _, ok := params["long"]; ok {
+		return []string{"--long"}
+	}
+	return nil
+}
+
+func TestProbeExternalBinary(t *testing.T) {
+	if _, err := exec.LookPath("echo"); err != nil {
+		t.Skip("echo not available")
+	}
+	base, err := NewExternalCodec(ExternalDefinition{
+		Name:           "probe-echo",
+		Binary:         "echo",
+		Levels:         "1..22",
+		IO:             "stdout",
+		Compress:       []string{"-{level}", "{input}"},
+		Decompress:     []string{"{input}"},
+		VersionCommand: []string{"fake tool v9.8.7"},
+	})
+	if err != nil {
+		t.Fatalf("NewExternalCodec failed: %v", err)
+	}
+	c := &echoCodec{ExternalCodec: base.(*ExternalCodec), help: []string{"usage: --ultra -T#"}}
+	defer func() {
+		probeMux.Lock()
+		delete(probeCache, c.Name())
+		probeMux.Unlock()
+	}()
+
+	caps := Probe(c)
+	if caps.Version != "9.8.7" {
+		t.Errorf("Expected version 9.8.7, got %q", caps.Version)
+	}
+	if !strings.HasSuffix(caps.Path, "echo") {
+		t.Errorf("Expected resolved echo path, got %q", caps.Path)
+	}
+	if !reflect.DeepEqual(caps.Flags, map[string]bool{"--ultra": true, "--long": false}) {
+		t.Errorf("Unexpected flags %v", caps.Flags)
+	}
+
+	if missing := UnsupportedFlags(c, 22, nil); len(missing) != 0 {
+		t.Errorf("Expected --ultra to be supported, missing %v", missing)
+	}
+	if missing := UnsupportedFlags(c, 3, Params{"long": "27"}); !reflect.DeepEqual(missing, []string{"--long"}) {
+		t.Errorf("Expected --long to be missing, got %v", missing)
+	}
+}
+
+func TestProbeInProcess(t *testing.T) {
+	if v := Probe(Registry["go-gzip"]).Version; !strings.HasPrefix(v, "go") {
+		t.Errorf("Expected the Go version for a standard library codec, got %q", v)
+	}
+	if v := Probe(Registry["go-zstd"]).Version; !strings.HasPrefix(v, "github.com/klauspost/compress") {
+		t.Errorf("Expected the module path for go-zstd, got %q", v)
+	}
+}
+
+func TestZstdThreadsFollowProbe(t *testing.T) {
+	z := &ZstdCodec{}
+	probeMux.Lock()
+	saved, cached := probeCache[z.Name()]
+	probeCache[z.Name()] = Capabilities{Flags: map[string]bool{"-T#": false}}
+	probeMux.Unlock()
+	defer func() {
+		probeMux.Lock()
+		delete(probeCache, z.Name())
+		if cached {
+			probeCache[z.Name()] = saved
+		}
+		probeMux.Unlock()
+	}()
+
+	if z.SupportsThreading() {
+		t.Error("Expected no threading without -T#")
+	}
+	for _, args := range [][]string{
+		z.CompressCommand(3, 4, "in", "out"),
+		z.DecompressCommand(4, "in", "out"),
+		z.StreamCompressCommand(3, 4),
+		z.StreamDecompressCommand(4),
+	} {
+		for _, arg := range args {
+			if strings.HasPrefix(arg, "-T") {
+				t.Errorf("Unexpected thread argument in %v", args)
+			}
+		}
+	}
+}
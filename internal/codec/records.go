@@ -0,0 +1,36 @@
+package codec
+
+import "io"
+
+// RecordCodec is implemented by in-process codecs whose library can reuse
+// one encoder and decoder across many small, independently compressed
+// records, as message-oriented callers do. Codecs without it compress each
+// record with a fresh Compress call.
+type RecordCodec interface {
+	NewRecordCompressor(level, threads int) (RecordCoder, error)
+	NewRecordDecompressor(threads int) (RecordCoder, error)
+}
+
+// RecordCoder compresses or decompresses whole records
+type RecordCoder interface {
+	// Append appends the compressed or decompressed form of src to dst
+	Append(dst, src []byte) ([]byte, error)
+	io.Closer
+}
+
+// recordFunc adapts library functions to RecordCoder
+type recordFunc struct {
+	append func(dst, src []byte) ([]byte, error)
+	close  func() error
+}
+
+func (r recordFunc) Append(dst, src []byte) ([]byte, error) {
+	return r.append(dst, src)
+}
+
+func (r recordFunc) Close() error {
+	if r.close == nil {
+		return nil
+	}
+	return r.close()
+}
@@ -0,0 +1,54 @@
+package codec
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestRecordCodecsRoundTrip(t *testing.T) {
+	records := [][]byte{
+		[]byte(`{"id":1,"event":"login","status":"ok"}`),
+		[]byte(`{"id":2,"event":"logout","status":"ok"}`),
+		{},
+	}
+
+	for name, c := range Registry {
+		rc, ok := c.(RecordCodec)
+		if !ok {
+			continue
+		}
+		levels := c.Levels()
+		for _, level := range []int{levels[0], levels[len(levels)-1]} {
+			t.Run(fmt.Sprintf("%s/level%d", name, level), func(t *testing.T) {
+				enc, err := rc.NewRecordCompressor(level, 1)
+				if err != nil {
+					t.Fatalf("NewRecordCompressor level %d failed: %v", level, err)
+				}
+				defer func() { _ = enc.Close() }()
+				dec, err := rc.NewRecordDecompressor(1)
+				if err != nil {
+					t.Fatalf("NewRecordDecompressor failed: %v", err)
+				}
+				defer func() { _ = dec.Close() }()
+
+				// Each record must decode on its own, in any order
+				compressed := make([][]byte, len(records))
+				for i, record := range records {
+					if compressed[i], err = enc.Append(nil, record); err != nil {
+						t.Fatalf("Compressing record %d failed: %v", i, err)
+					}
+				}
+				for i := len(records) - 1; i >= 0; i-- {
+					out, err := dec.Append([]byte("prefix"), compressed[i])
+					if err != nil {
+						t.Fatalf("Decompressing record %d failed: %v", i, err)
+					}
+					if !bytes.Equal(out, append([]byte("prefix"), records[i]...)) {
+						t.Errorf("Record %d round trip at level %d = %q", i, level, out)
+					}
+				}
+			})
+		}
+	}
+}
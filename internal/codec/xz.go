@@ -3,6 +3,8 @@ package codec
 import (
 	"fmt"
 	"os/exec"
+	"strconv"
+	"strings"
 )
 
 type XzCodec struct{}
@@ -32,14 +34,132 @@ func (x *XzCodec) IsAvailable() bool {
 	return err == nil
 }
 
-func (x *XzCodec) CompressCommand(level, threads int, input, output string) []string {
-	args := []string{fmt.Sprintf("-%d", level), fmt.Sprintf("-T%d", threads), "-c", input}
-	if level == 9 {
-		args = append([]string{"-e"}, args...)
+func (x *XzCodec) ValidateLevel(level int) error {
+	if level >= 0 && level <= 9 {
+		return nil
+	}
+	return fmt.Errorf("xz does not support level %d (valid: 0..9, or 0e..9e for the extreme presets)", level)
+}
+
+// LevelSuffixes maps the "e" suffix of xz's own preset syntax to the extreme
+// param, so "9e" selects preset 9 with -e
+func (x *XzCodec) LevelSuffixes() map[string]Params {
+	return map[string]Params{"e": {"extreme": "true"}}
+}
+
+// extreme reports whether a configuration uses the extreme variant of its
+// preset. Level 9 does unless the extreme param turns it off.
+func (x *XzCodec) extreme(level int, params Params) bool {
+	if v, ok := params["extreme"]; ok {
+		return isTrue(v)
+	}
+	return level == 9
+}
+
+func (x *XzCodec) preset(level int, params Params) string {
+	if x.extreme(level, params) {
+		return strconv.Itoa(level) + "e"
+	}
+	return strconv.Itoa(level)
+}
+
+func (x *XzCodec) presetArgs(level int, params Params) []string {
+	if x.extreme(level, params) {
+		return []string{fmt.Sprintf("-%d", level), "-e"}
 	}
-	return args
+	return []string{fmt.Sprintf("-%d", level)}
+}
+
+// lzma2Options returns the params that go into an --lzma2 option string
+func (x *XzCodec) lzma2Options(params Params) []string {
+	return subOptions(params, "dict", "lc", "lp", "pb", "mf", "mode", "nice", "depth")
+}
+
+// paramArgs selects the filter chain: --lzma2 on top of the preset when
+// LZMA2 options are set, since a preset after --lzma2 would replace the
+// chain, and the plain preset otherwise
+func (x *XzCodec) paramArgs(level int, params Params) []string {
+	if args := x.CompressParamArgs(level, params); len(args) > 0 {
+		return args
+	}
+	return x.presetArgs(level, params)
+}
+
+func (x *XzCodec) IOMode() IOMode {
+	return IOStdout
+}
+
+func (x *XzCodec) CompressCommand(level, threads int, input, output string) []string {
+	return append(x.presetArgs(level, nil), fmt.Sprintf("-T%d", threads), "-c", input)
+}
+
+func (x *XzCodec) CompressParamCommand(level, threads int, params Params, input, output string) []string {
+	return append(x.paramArgs(level, params), fmt.Sprintf("-T%d", threads), "-c", input)
 }
 
 func (x *XzCodec) DecompressCommand(threads int, input, output string) []string {
 	return []string{"-d", fmt.Sprintf("-T%d", threads), "-c", input}
 }
+
+func (x *XzCodec) StreamCompressCommand(level, threads int) []string {
+	return append(x.presetArgs(level, nil), fmt.Sprintf("-T%d", threads), "-c")
+}
+
+func (x *XzCodec) StreamCompressParamCommand(level, threads int, params Params) []string {
+	return append(x.paramArgs(level, params), fmt.Sprintf("-T%d", threads), "-c")
+}
+
+func (x *XzCodec) StreamDecompressCommand(threads int) []string {
+	return []string{"-d", fmt.Sprintf("-T%d", threads), "-c"}
+}
+
+func (x *XzCodec) SupportedParams() []ParamSpec {
+	return []ParamSpec{
+		{"dict", "LZMA2 dictionary size, e.g. 64MiB"},
+		{"lc", "literal context bits"},
+		{"lp", "literal position bits"},
+		{"pb", "position bits"},
+		{"mf", "match finder: hc3, hc4, bt2, bt3 or bt4"},
+		{"mode", "compression mode: fast or normal"},
+		{"nice", "nice length of a match"},
+		{"depth", "maximum match finder search depth"},
+		{"extreme", "extreme variant of the preset: true or false (default true at level 9)"},
+	}
+}
+
+// CompressParamArgs builds an --lzma2 option string on top of the level's
+// preset, since --lzma2 otherwise replaces the preset entirely
+func (x *XzCodec) CompressParamArgs(level int, params Params) []string {
+	opts := x.lzma2Options(params)
+	if len(opts) == 0 {
+		return nil
+	}
+	return []string{"--lzma2=preset=" + x.preset(level, params) + "," + strings.Join(opts, ",")}
+}
+
+func (x *XzCodec) DecompressParamArgs(params Params) []string {
+	return nil
+}
+
+func (x *XzCodec) VersionArgs() []string {
+	return []string{"--version"}
+}
+
+func (x *XzCodec) HelpArgs() []string {
+	return []string{"--long-help"}
+}
+
+func (x *XzCodec) OptionalFlags() []string {
+	return []string{"--threads", "--extreme", "--lzma2"}
+}
+
+func (x *XzCodec) RequiredFlags(level int, params Params) []string {
+	flags := []string{"--threads"}
+	if x.extreme(level, params) {
+		flags = append(flags, "--extreme")
+	}
+	if len(x.lzma2Options(params)) > 0 {
+		flags = append(flags, "--lzma2")
+	}
+	return flags
+}
@@ -3,6 +3,7 @@ package codec
 import (
 	"fmt"
 	"os/exec"
+	"strings"
 )
 
 type ZstdCodec struct{}
@@ -23,8 +24,17 @@ func (z *ZstdCodec) Levels() []int {
 	return MakeRange(1, 19)
 }
 
+// SupportsThreading reports whether the installed binary accepts -T#, which
+// builds without multithreading leave out
 func (z *ZstdCodec) SupportsThreading() bool {
-	return true
+	return Probe(z).Supports("-T#")
+}
+
+func (z *ZstdCodec) threadArgs(threads int) []string {
+	if !z.SupportsThreading() {
+		return nil
+	}
+	return []string{fmt.Sprintf("-T%d", threads)}
 }
 
 func (z *ZstdCodec) IsAvailable() bool {
@@ -32,14 +42,120 @@ func (z *ZstdCodec) IsAvailable() bool {
 	return err == nil
 }
 
+// ValidateLevel accepts the regular levels 1-22, where 20 and above need
+// --ultra, and negative levels, which select --fast=N
+func (z *ZstdCodec) ValidateLevel(level int) error {
+	if level == 0 || level > 22 {
+		return fmt.Errorf("zstd does not support level %d (valid: negative fast levels, 1..22)", level)
+	}
+	return nil
+}
+
+func (z *ZstdCodec) levelArgs(level int) []string {
+	switch {
+	case level < 0:
+		return []string{fmt.Sprintf("--fast=%d", -level)}
+	case level > 19:
+		return []string{"--ultra", fmt.Sprintf("-%d", level)}
+	default:
+		return []string{fmt.Sprintf("-%d", level)}
+	}
+}
+
+func (z *ZstdCodec) IOMode() IOMode {
+	return IOFile
+}
+
 func (z *ZstdCodec) CompressCommand(level, threads int, input, output string) []string {
-	args := []string{fmt.Sprintf("-%d", level), fmt.Sprintf("-T%d", threads), "-q", "-f", "-o", output, input}
-	if level == 19 {
-		args = append([]string{"--ultra", "--long=31"}, args...)
+	args := append(z.levelArgs(level), z.threadArgs(threads)...)
+	return append(args, "-q", "-f", "-o", output, input)
+}
+
+func (z *ZstdCodec) DecompressCommand(threads int, input, output string) []string {
+	args := append([]string{"-d"}, z.threadArgs(threads)...)
+	return append(args, "-q", "-f", "-o", output, input)
+}
+
+func (z *ZstdCodec) StreamCompressCommand(level, threads int) []string {
+	args := append(z.levelArgs(level), z.threadArgs(threads)...)
+	return append(args, "-q", "-c")
+}
+
+func (z *ZstdCodec) StreamDecompressCommand(threads int) []string {
+	args := append([]string{"-d"}, z.threadArgs(threads)...)
+	return append(args, "-q", "-c")
+}
+
+func (z *ZstdCodec) SupportedParams() []ParamSpec {
+	return []ParamSpec{
+		{"long", "long distance matching window log (--long=N)"},
+		{"wlog", "window log (--zstd=wlog=N)"},
+		{"hlog", "hash table log (--zstd=hlog=N)"},
+		{"clog", "chain table log (--zstd=clog=N)"},
+		{"slog", "search log (--zstd=slog=N)"},
+		{"mml", "minimum match length (--zstd=mml=N)"},
+		{"tlen", "target length (--zstd=tlen=N)"},
+		{"strat", "strategy, 1 (fast) to 9 (btultra2) (--zstd=strat=N)"},
+	}
+}
+
+func (z *ZstdCodec) CompressParamArgs(level int, params Params) []string {
+	args := make([]string, 0)
+	if v, ok := params["long"]; ok {
+		args = append(args, "--long="+v)
+	}
+	if opts := subOptions(params, "wlog", "hlog", "clog", "slog", "mml", "tlen", "strat"); len(opts) > 0 {
+		args = append(args, "--zstd="+strings.Join(opts, ","))
 	}
 	return args
 }
 
-func (z *ZstdCodec) DecompressCommand(threads int, input, output string) []string {
-	return []string{"-d", fmt.Sprintf("-T%d", threads), "-q", "-f", "-o", output, input}
+// DecompressParamArgs raises the decoder's window limit to match a large
+// compression window
+func (z *ZstdCodec) DecompressParamArgs(params Params) []string {
+	if v, ok := params["long"]; ok {
+		return []string{"--long=" + v}
+	}
+	if v, ok := params["wlog"]; ok {
+		return []string{"--long=" + v}
+	}
+	return nil
+}
+
+func (z *ZstdCodec) VersionArgs() []string {
+	return []string{"-V"}
+}
+
+func (z *ZstdCodec) HelpArgs() []string {
+	return []string{"-H"}
+}
+
+func (z *ZstdCodec) OptionalFlags() []string {
+	return []string{"--ultra", "--fast", "--long", "-T#", "--train"}
+}
+
+func (z *ZstdCodec) RequiredFlags(level int, params Params) []string {
+	flags := make([]string, 0)
+	switch {
+	case level < 0:
+		flags = append(flags, "--fast")
+	case level > 19:
+		flags = append(flags, "--ultra")
+	}
+	if _, ok := params["long"]; ok {
+		flags = append(flags, "--long")
+	}
+	return flags
+}
+
+func (z *ZstdCodec) DictionaryFlag() string {
+	return "--train"
+}
+
+func (z *ZstdCodec) TrainDictionaryArgs(sampleList string, size int64, output string) []string {
+	return []string{"--train", "--filelist", sampleList, fmt.Sprintf("--maxdict=%d", size), "-q", "-f", "-o", output}
+}
+
+func (z *ZstdCodec) DictionaryArgs(dict string) []string {
+	return []string{"-D", dict}
 }
@@ -0,0 +1,175 @@
+// Package corpus generates deterministic benchmark inputs: seeded synthetic
+// datasets of a requested size and tar streams of directory trees.
+package corpus
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aomarai/compstat/internal/util"
+)
+
+// KindTar assembles a directory tree into a tar stream instead of
+// generating data
+const KindTar = "tar"
+
+// KindConcat labels inputs concatenated into one tar stream
+const KindConcat = "concat"
+
+// DefaultSeed is used when a spec does not give one
+const DefaultSeed = 1
+
+// Spec describes one generated input. It is written as kind:size[@seed],
+// e.g. json:1GiB or random:256MiB@7, or as tar:dir for a directory tree.
+type Spec struct {
+	Kind string
+	Size int64
+	Seed uint64
+	// Dir is the directory archived by tar specs
+	Dir string
+}
+
+// ParseSpec parses a single generator spec
+func ParseSpec(spec string) (Spec, error) {
+	spec = strings.TrimSpace(spec)
+	kind, arg, ok := strings.Cut(spec, ":")
+	if !ok || arg == "" {
+		return Spec{}, fmt.Errorf("invalid generator %q (want kind:size or tar:dir)", spec)
+	}
+	if kind == KindTar {
+		return Spec{Kind: kind, Dir: filepath.Clean(arg)}, nil
+	}
+	if _, known := generators[kind]; !known {
+		return Spec{}, fmt.Errorf("unknown generator kind %q (available: %s)", kind, strings.Join(Kinds(), ", "))
+	}
+
+	s := Spec{Kind: kind, Seed: DefaultSeed}
+	size, seed, hasSeed := strings.Cut(arg, "@")
+	var err error
+	if s.Size, err = util.ParseSize(size); err != nil {
+		return Spec{}, fmt.Errorf("generator %q: %w", spec, err)
+	}
+	if s.Size == 0 {
+		return Spec{}, fmt.Errorf("generator %q: size must be positive", spec)
+	}
+	if hasSeed {
+		if s.Seed, err = strconv.ParseUint(seed, 10, 64); err != nil {
+			return Spec{}, fmt.Errorf("generator %q: invalid seed %q", spec, seed)
+		}
+	}
+	return s, nil
+}
+
+// ParseSpecs parses a comma-separated list of generator specs
+func ParseSpecs(list string) ([]Spec, error) {
+	specs := make([]Spec, 0)
+	for _, part := range strings.Split(list, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		s, err := ParseSpec(part)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, s)
+	}
+	return specs, nil
+}
+
+// String returns the canonical form of the spec, which results record in
+// place of a file path
+func (s Spec) String() string {
+	if s.Kind == KindTar {
+		return KindTar + ":" + s.Dir
+	}
+	str := s.Kind + ":" + util.FormatSize(s.Size)
+	if s.Seed != DefaultSeed {
+		str += "@" + strconv.FormatUint(s.Seed, 10)
+	}
+	return str
+}
+
+// FileName returns a file name for the generated data
+func (s Spec) FileName() string {
+	if s.Kind == KindTar {
+		// Directories with the same base name get different files
+		dir := filepath.Clean(s.Dir)
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(dir))
+		return fmt.Sprintf("tar-%s-%08x.tar", filepath.Base(dir), h.Sum32())
+	}
+	name := s.Kind + "-" + util.FormatSize(s.Size)
+	if s.Seed != DefaultSeed {
+		name += "-s" + strconv.FormatUint(s.Seed, 10)
+	}
+	return name + ".dat"
+}
+
+// ConcatLabel returns the name recorded for the concatenation of the inputs
+// given by patterns
+func ConcatLabel(patterns []string) string {
+	return KindConcat + ":" + strings.Join(patterns, ",")
+}
+
+// DisplayName shortens a recorded input for tables: file paths become their
+// base name, while generator specs and concatenation labels are kept whole
+func DisplayName(input string) string {
+	if strings.HasPrefix(input, KindConcat+":") {
+		return input
+	}
+	if _, err := ParseSpec(input); err == nil {
+		return input
+	}
+	return filepath.Base(input)
+}
+
+// Kinds returns the names of the available generators, including tar
+func Kinds() []string {
+	kinds := make([]string, 0, len(generators)+1)
+	for kind := range generators {
+		kinds = append(kinds, kind)
+	}
+	kinds = append(kinds, KindTar)
+	sort.Strings(kinds)
+	return kinds
+}
+
+// Generate writes the data described by s to w. The output depends only on
+// the spec, or for tar specs on the directory contents.
+func Generate(w io.Writer, s Spec) error {
+	if s.Kind == KindTar {
+		return TarDirectory(w, s.Dir)
+	}
+	gen, ok := generators[s.Kind]
+	if !ok {
+		return fmt.Errorf("unknown generator kind %q", s.Kind)
+	}
+	_, err := io.CopyN(w, gen(s.Seed), s.Size)
+	return err
+}
+
+// WriteFile generates s into path. The data is written to a temporary file
+// first, so an interrupted run never leaves a truncated input behind.
+func WriteFile(path string, s Spec) error {
+	tmp := path + ".part"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := Generate(f, s); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return fmt.Errorf("generating %s: %w", s, err)
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
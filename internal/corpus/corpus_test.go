@@ -0,0 +1,177 @@
+package corpus
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/flate"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseSpecs(t *testing.T) {
+	specs, err := ParseSpecs("json:1GiB, random:256MiB@7,tar:testdata/tree")
+	if err != nil {
+		t.Fatalf("ParseSpecs failed: %v", err)
+	}
+	want := []Spec{
+		{Kind: "json", Size: 1 << 30, Seed: DefaultSeed},
+		{Kind: "random", Size: 256 << 20, Seed: 7},
+		{Kind: KindTar, Dir: "testdata/tree"},
+	}
+	if len(specs) != len(want) {
+		t.Fatalf("Got %d specs, want %d", len(specs), len(want))
+	}
+	for i := range want {
+		if specs[i] != want[i] {
+			t.Errorf("Spec %d = %+v, want %+v", i, specs[i], want[i])
+		}
+	}
+	if got := specs[1].String(); got != "random:256MiB@7" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := specs[0].FileName(); got != "json-1GiB.dat" {
+		t.Errorf("FileName() = %q", got)
+	}
+
+	for _, spec := range []string{"json", "json:", "nosuch:1MiB", "json:big", "json:0", "json:1MiB@x"} {
+		if _, err := ParseSpec(spec); err == nil {
+			t.Errorf("ParseSpec(%q) succeeded, want error", spec)
+		}
+	}
+}
+
+func generate(t *testing.T, spec string) []byte {
+	t.Helper()
+	s, err := ParseSpec(spec)
+	if err != nil {
+		t.Fatalf("ParseSpec(%q) failed: %v", spec, err)
+	}
+	var buf bytes.Buffer
+	if err := Generate(&buf, s); err != nil {
+		t.Fatalf("Generate(%q) failed: %v", spec, err)
+	}
+	return buf.Bytes()
+}
+
+func compressedRatio(t *testing.T, data []byte) float64 {
+	t.Helper()
+	var buf bytes.Buffer
+	w, _ := flate.NewWriter(&buf, flate.BestSpeed)
+	_, _ = w.Write(data)
+	_ = w.Close()
+	return float64(len(data)) / float64(buf.Len())
+}
+
+func TestGenerateDeterministic(t *testing.T) {
+	for _, kind := range Kinds() {
+		if kind == KindTar {
+			continue
+		}
+		t.Run(kind, func(t *testing.T) {
+			a := generate(t, kind+":100KiB")
+			if len(a) != 100<<10 {
+				t.Fatalf("Generated %d bytes, want %d", len(a), 100<<10)
+			}
+			if !bytes.Equal(a, generate(t, kind+":100KiB")) {
+				t.Error("Same seed produced different data")
+			}
+			if kind != "zeros" && bytes.Equal(a, generate(t, kind+":100KiB@2")) {
+				t.Error("Different seeds produced the same data")
+			}
+		})
+	}
+}
+
+func TestGenerateCompressibility(t *testing.T) {
+	if r := compressedRatio(t, generate(t, "random:256KiB")); r > 1.01 {
+		t.Errorf("Random data compressed %.2fx", r)
+	}
+	if r := compressedRatio(t, generate(t, "zeros:256KiB")); r < 100 {
+		t.Errorf("Zeros compressed only %.2fx", r)
+	}
+	for _, kind := range []string{"text", "logs", "json", "csv", "mixed"} {
+		if r := compressedRatio(t, generate(t, kind+":256KiB")); r < 1.5 || r > 20 {
+			t.Errorf("%s compressed %.2fx, want a realistic ratio", kind, r)
+		}
+	}
+}
+
+func TestGenerateStructuredRecords(t *testing.T) {
+	data := generate(t, "json:64KiB")
+	lines := strings.Split(string(data), "\n")
+	for _, line := range lines[:len(lines)-1] {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Invalid JSON record %q: %v", line, err)
+		}
+	}
+
+	data = generate(t, "csv:64KiB")
+	rows, err := csv.NewReader(bytes.NewReader(data[:bytes.LastIndexByte(data, '\n')+1])).ReadAll()
+	if err != nil {
+		t.Fatalf("Invalid CSV: %v", err)
+	}
+	if rows[0][0] != "id" || len(rows) < 100 {
+		t.Errorf("Unexpected CSV header %v or row count %d", rows[0], len(rows))
+	}
+}
+
+func TestTarDirectory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{"a.txt": "alpha", "sub/b.txt": "beta"}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data := generate(t, "tar:"+dir)
+	if !bytes.Equal(data, generate(t, "tar:"+dir)) {
+		t.Error("Archiving the same tree twice produced different streams")
+	}
+
+	names := make([]string, 0)
+	tr := tar.NewReader(bytes.NewReader(data))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Reading tar failed: %v", err)
+		}
+		names = append(names, hdr.Name)
+		if want, ok := files[hdr.Name]; ok {
+			content, _ := io.ReadAll(tr)
+			if string(content) != want {
+				t.Errorf("%s contains %q, want %q", hdr.Name, content, want)
+			}
+		}
+	}
+	if got := strings.Join(names, " "); got != "a.txt sub/ sub/b.txt" {
+		t.Errorf("Tar entries = %q", got)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs.dat")
+	s := Spec{Kind: "logs", Size: 10000, Seed: DefaultSeed}
+	if err := WriteFile(path, s); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Size() != 10000 {
+		t.Errorf("Expected a 10000 byte file, got %v, %v", info, err)
+	}
+	if _, err := os.Stat(path + ".part"); !os.IsNotExist(err) {
+		t.Error("Temporary file was left behind")
+	}
+}
@@ -0,0 +1,236 @@
+package corpus
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"time"
+)
+
+// generators maps each kind to a constructor for its endless data stream
+var generators = map[string]func(seed uint64) io.Reader{
+	"random": randomReader,
+	"zeros":  func(uint64) io.Reader { return zeroReader{} },
+	"text":   func(seed uint64) io.Reader { return newRecordReader(seed, appendSentence) },
+	"logs":   func(seed uint64) io.Reader { return newRecordReader(seed, appendLogLine) },
+	"json":   func(seed uint64) io.Reader { return newRecordReader(seed, appendJSONRecord) },
+	"csv":    func(seed uint64) io.Reader { return newRecordReader(seed, appendCSVRow) },
+	"mixed":  mixedReader,
+}
+
+// epoch is the first timestamp in generated logs and records
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+var words = []string{
+	"the", "of", "and", "to", "in", "is", "that", "for", "it", "as", "was", "with",
+	"be", "by", "on", "not", "he", "this", "are", "or", "his", "from", "at", "which",
+	"but", "have", "an", "had", "they", "you", "were", "their", "one", "all", "we",
+	"can", "her", "has", "there", "been", "if", "more", "when", "will", "would",
+	"who", "so", "no", "data", "system", "compression", "file", "level", "time",
+	"stream", "block", "window", "archive", "result", "measure", "server", "network",
+	"memory", "process", "value", "record", "table", "index", "query", "cluster",
+}
+
+var (
+	logLevels = []string{"DEBUG", "INFO", "INFO", "INFO", "INFO", "WARN", "ERROR"}
+	services  = []string{"api", "auth", "billing", "search", "storage", "worker"}
+	methods   = []string{"GET", "GET", "GET", "POST", "PUT", "DELETE"}
+	paths     = []string{"/v1/users", "/v1/orders", "/v1/items", "/v1/search", "/health", "/v1/login"}
+	statuses  = []int{200, 200, 200, 200, 201, 204, 304, 400, 404, 500}
+	regions   = []string{"us-east", "us-west", "eu-central", "ap-south", "sa-east"}
+	products  = []string{"widget", "gadget", "sprocket", "gizmo", "doohickey", "thingamajig"}
+)
+
+// newRand returns the deterministic generator for seed
+func newRand(seed uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(seed, 0x636f6d7073746174))
+}
+
+// randomReader produces incompressible bytes
+func randomReader(seed uint64) io.Reader {
+	var key [32]byte
+	binary.LittleEndian.PutUint64(key[:], seed)
+	return rand.NewChaCha8(key)
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+// recordReader produces a stream of records, each appended by next. n is
+// the number of records produced so far.
+type recordReader struct {
+	rng  *rand.Rand
+	next func(buf []byte, rng *rand.Rand, n int) []byte
+	n    int
+	buf  []byte
+	off  int
+}
+
+func newRecordReader(seed uint64, next func(buf []byte, rng *rand.Rand, n int) []byte) *recordReader {
+	return &recordReader{rng: newRand(seed), next: next}
+}
+
+func (r *recordReader) Read(p []byte) (int, error) {
+	total := 0
+	for total < len(p) {
+		if r.off == len(r.buf) {
+			r.buf = r.next(r.buf[:0], r.rng, r.n)
+			r.n++
+			r.off = 0
+		}
+		n := copy(p[total:], r.buf[r.off:])
+		r.off += n
+		total += n
+	}
+	return total, nil
+}
+
+func pick[T any](rng *rand.Rand, values []T) T {
+	return values[rng.IntN(len(values))]
+}
+
+// appendSentence appends a line of English-like text from a small vocabulary
+func appendSentence(buf []byte, rng *rand.Rand, _ int) []byte {
+	count := 5 + rng.IntN(12)
+	for i := 0; i < count; i++ {
+		word := pick(rng, words)
+		if i == 0 {
+			buf = append(buf, word[0]-'a'+'A')
+			buf = append(buf, word[1:]...)
+			continue
+		}
+		buf = append(buf, ' ')
+		buf = append(buf, word...)
+	}
+	return append(buf, ".\n"...)
+}
+
+// timestamp returns the time of record n, advancing about 50ms per record
+func timestamp(n int) time.Time {
+	return epoch.Add(time.Duration(n) * 50 * time.Millisecond)
+}
+
+// appendLogLine appends an access-log style line
+func appendLogLine(buf []byte, rng *rand.Rand, n int) []byte {
+	buf = timestamp(n).Add(time.Duration(rng.IntN(50))*time.Millisecond).AppendFormat(buf, "2006-01-02T15:04:05.000Z")
+	buf = append(buf, ' ')
+	buf = append(buf, pick(rng, logLevels)...)
+	buf = append(buf, " ["...)
+	buf = append(buf, pick(rng, services)...)
+	buf = append(buf, "] request_id="...)
+	buf = strconv.AppendUint(buf, rng.Uint64()>>16, 16)
+	buf = append(buf, " method="...)
+	buf = append(buf, pick(rng, methods)...)
+	buf = append(buf, " path="...)
+	buf = append(buf, pick(rng, paths)...)
+	if rng.IntN(3) == 0 {
+		buf = append(buf, '/')
+		buf = strconv.AppendInt(buf, int64(rng.IntN(100000)), 10)
+	}
+	buf = append(buf, " status="...)
+	buf = strconv.AppendInt(buf, int64(pick(rng, statuses)), 10)
+	buf = append(buf, " latency_ms="...)
+	buf = strconv.AppendInt(buf, int64(rng.ExpFloat64()*40), 10)
+	return append(buf, '\n')
+}
+
+// appendJSONRecord appends one newline-delimited JSON object
+func appendJSONRecord(buf []byte, rng *rand.Rand, n int) []byte {
+	user := pick(rng, words) + "_" + pick(rng, words)
+	buf = append(buf, `{"id":`...)
+	buf = strconv.AppendInt(buf, int64(n+1), 10)
+	buf = append(buf, `,"user":"`...)
+	buf = append(buf, user...)
+	buf = append(buf, `","email":"`...)
+	buf = append(buf, user...)
+	buf = append(buf, `@example.com","active":`...)
+	buf = strconv.AppendBool(buf, rng.IntN(4) != 0)
+	buf = append(buf, `,"score":`...)
+	buf = strconv.AppendFloat(buf, math.Round(rng.Float64()*10000)/100, 'f', -1, 64)
+	buf = append(buf, `,"region":"`...)
+	buf = append(buf, pick(rng, regions)...)
+	buf = append(buf, `","tags":[`...)
+	for i, count := 0, rng.IntN(4); i < count; i++ {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, '"')
+		buf = append(buf, pick(rng, words)...)
+		buf = append(buf, '"')
+	}
+	buf = append(buf, `],"created_at":"`...)
+	buf = timestamp(n).AppendFormat(buf, time.RFC3339)
+	return append(buf, "\"}\n"...)
+}
+
+// appendCSVRow appends one sales table row, preceded by the header
+func appendCSVRow(buf []byte, rng *rand.Rand, n int) []byte {
+	if n == 0 {
+		buf = append(buf, "id,timestamp,region,product,quantity,unit_price,total\n"...)
+	}
+	quantity := 1 + rng.IntN(20)
+	price := math.Round((1+rng.Float64()*99)*100) / 100
+	buf = strconv.AppendInt(buf, int64(n+1), 10)
+	buf = append(buf, ',')
+	buf = timestamp(n).AppendFormat(buf, time.DateTime)
+	buf = append(buf, ',')
+	buf = append(buf, pick(rng, regions)...)
+	buf = append(buf, ',')
+	buf = append(buf, pick(rng, products)...)
+	buf = append(buf, ',')
+	buf = strconv.AppendInt(buf, int64(quantity), 10)
+	buf = append(buf, ',')
+	buf = strconv.AppendFloat(buf, price, 'f', 2, 64)
+	buf = append(buf, ',')
+	buf = strconv.AppendFloat(buf, price*float64(quantity), 'f', 2, 64)
+	return append(buf, '\n')
+}
+
+// appendBinaryRecord appends a fixed-size little-endian record of the kind
+// found in binary formats: a sequence number, a small counter and a float
+func appendBinaryRecord(buf []byte, rng *rand.Rand, n int) []byte {
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(n))
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(rng.IntN(64)))
+	buf = binary.LittleEndian.AppendUint16(buf, 0)
+	return binary.LittleEndian.AppendUint32(buf, math.Float32bits(float32(rng.NormFloat64())))
+}
+
+// mixedReader interleaves blocks of 4KiB to 64KiB drawn from random bytes,
+// zeros, text and binary records, like a typical executable or disk image
+func mixedReader(seed uint64) io.Reader {
+	return &mixed{
+		rng: newRand(seed),
+		sources: []io.Reader{
+			randomReader(seed),
+			zeroReader{},
+			newRecordReader(seed+1, appendSentence),
+			newRecordReader(seed+2, appendBinaryRecord),
+		},
+	}
+}
+
+type mixed struct {
+	rng     *rand.Rand
+	sources []io.Reader
+	current io.Reader
+	left    int
+}
+
+func (m *mixed) Read(p []byte) (int, error) {
+	if m.left == 0 {
+		m.current = pick(m.rng, m.sources)
+		m.left = 4<<10 + m.rng.IntN(60<<10)
+	}
+	if len(p) > m.left {
+		p = p[:m.left]
+	}
+	n, err := m.current.Read(p)
+	m.left -= n
+	return n, err
+}
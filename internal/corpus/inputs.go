@@ -0,0 +1,167 @@
+package corpus
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ExpandPaths resolves input patterns into a list of regular files. Each
+// pattern is a file, a directory (all files below it), a glob where **
+// matches any number of directories, or @listfile naming a file with one
+// pattern per line. Files are listed once, in the order first matched.
+func ExpandPaths(patterns []string) ([]string, error) {
+	seen := make(map[string]bool)
+	files := make([]string, 0)
+	for _, pattern := range patterns {
+		matched, err := expandPattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range matched {
+			if !seen[f] {
+				seen[f] = true
+				files = append(files, f)
+			}
+		}
+	}
+	return files, nil
+}
+
+func expandPattern(pattern string) ([]string, error) {
+	pattern = strings.TrimSpace(pattern)
+	if list, ok := strings.CutPrefix(pattern, "@"); ok {
+		return expandListFile(list)
+	}
+	if strings.ContainsAny(pattern, "*?[") {
+		return expandGlob(pattern)
+	}
+
+	info, err := os.Stat(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		files, err := walkFiles(pattern, func(string) bool { return true })
+		if err == nil && len(files) == 0 {
+			err = fmt.Errorf("directory %s contains no files", pattern)
+		}
+		return files, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a regular file", pattern)
+	}
+	return []string{pattern}, nil
+}
+
+// expandListFile expands each pattern listed in path, skipping blank lines
+// and lines starting with #
+func expandListFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func(f *os.File) {
+		err := f.Close()
+		if err != nil {
+			fmt.Printf("warning: failed to close file: %v\n", err)
+		}
+	}(f)
+
+	files := make([]string, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		matched, err := expandPattern(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		files = append(files, matched...)
+	}
+	return files, scanner.Err()
+}
+
+// expandGlob walks the directory before the first wildcard and returns the
+// regular files whose paths match pattern
+func expandGlob(pattern string) ([]string, error) {
+	segments := strings.Split(filepath.ToSlash(pattern), "/")
+	for _, seg := range segments {
+		if _, err := path.Match(seg, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+
+	static := 0
+	for static < len(segments)-1 && !strings.ContainsAny(segments[static], "*?[") {
+		static++
+	}
+	root := strings.Join(segments[:static], "/")
+	if root == "" && static > 0 {
+		root = "/"
+	} else if root == "" {
+		root = "."
+	}
+	rest := segments[static:]
+
+	files, err := walkFiles(filepath.FromSlash(root), func(rel string) bool {
+		return matchSegments(rest, strings.Split(filepath.ToSlash(rel), "/"))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files match %q", pattern)
+	}
+	return files, nil
+}
+
+// walkFiles returns the regular files below root, in lexical order, whose
+// path relative to root satisfies match
+func walkFiles(root string, match func(rel string) bool) ([]string, error) {
+	files := make([]string, 0)
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		if match(rel) {
+			files = append(files, p)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// matchSegments matches path segments against pattern segments, where a
+// ** segment matches zero or more path segments
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], segments[0])
+	return ok && matchSegments(pattern[1:], segments[1:])
+}
@@ -0,0 +1,135 @@
+package corpus
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeTree creates files (relative slash paths) under a new temp directory
+func writeTree(t *testing.T, files ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestExpandPaths(t *testing.T) {
+	dir := writeTree(t, "a.log", "b.txt", "sub/c.log", "sub/deep/d.log", "sub/deep/e.txt")
+	join := func(names ...string) []string {
+		paths := make([]string, len(names))
+		for i, name := range names {
+			paths[i] = filepath.Join(dir, filepath.FromSlash(name))
+		}
+		return paths
+	}
+
+	list := filepath.Join(dir, "inputs.list")
+	content := "# inputs\n" + filepath.Join(dir, "b.txt") + "\n\n" + filepath.Join(dir, "sub", "deep", "*.txt") + "\n"
+	if err := os.WriteFile(list, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		patterns []string
+		want     []string
+	}{
+		{[]string{filepath.Join(dir, "a.log")}, join("a.log")},
+		{[]string{filepath.Join(dir, "sub")}, join("sub/c.log", "sub/deep/d.log", "sub/deep/e.txt")},
+		{[]string{filepath.Join(dir, "**", "*.log")}, join("a.log", "sub/c.log", "sub/deep/d.log")},
+		{[]string{filepath.Join(dir, "sub", "*", "*.log")}, join("sub/deep/d.log")},
+		{[]string{filepath.Join(dir, "*.log"), filepath.Join(dir, "a.log")}, join("a.log")},
+		{[]string{"@" + list}, join("b.txt", "sub/deep/e.txt")},
+	}
+	for _, tt := range tests {
+		got, err := ExpandPaths(tt.patterns)
+		if err != nil {
+			t.Errorf("ExpandPaths(%v) failed: %v", tt.patterns, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ExpandPaths(%v) = %v, want %v", tt.patterns, got, tt.want)
+		}
+	}
+
+	for _, pattern := range []string{
+		filepath.Join(dir, "missing.log"),
+		filepath.Join(dir, "**", "*.gz"),
+		filepath.Join(dir, "[.log"),
+		"@" + filepath.Join(dir, "missing.list"),
+	} {
+		if _, err := ExpandPaths([]string{pattern}); err == nil {
+			t.Errorf("ExpandPaths(%q) succeeded, want error", pattern)
+		}
+	}
+}
+
+func TestMatchSegments(t *testing.T) {
+	tests := []struct {
+		pattern, path []string
+		want          bool
+	}{
+		{[]string{"**", "*.log"}, []string{"a.log"}, true},
+		{[]string{"**", "*.log"}, []string{"x", "y", "a.log"}, true},
+		{[]string{"x", "**"}, []string{"x", "y", "a.log"}, true},
+		{[]string{"*.log"}, []string{"x", "a.log"}, false},
+		{[]string{"**", "y", "*"}, []string{"x", "z", "a.log"}, false},
+	}
+	for _, tt := range tests {
+		if got := matchSegments(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("matchSegments(%v, %v) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestTarFiles(t *testing.T) {
+	dir := writeTree(t, "one.txt", "sub/two.txt")
+	var buf bytes.Buffer
+	if err := TarFiles(&buf, []string{filepath.Join(dir, "sub", "two.txt"), filepath.Join(dir, "one.txt")}); err != nil {
+		t.Fatalf("TarFiles failed: %v", err)
+	}
+
+	tr := tar.NewReader(&buf)
+	for _, want := range []string{"sub/two.txt", "one.txt"} {
+		hdr, err := tr.Next()
+		if err != nil {
+			t.Fatalf("Reading tar failed: %v", err)
+		}
+		if filepath.IsAbs(hdr.Name) || filepath.Base(hdr.Name) != filepath.Base(want) {
+			t.Errorf("Entry %q, want a relative name ending in %s", hdr.Name, want)
+		}
+		content, _ := io.ReadAll(tr)
+		if string(content) != want {
+			t.Errorf("%s contains %q", hdr.Name, content)
+		}
+	}
+	if _, err := tr.Next(); err != io.EOF {
+		t.Errorf("Expected two entries, got more (%v)", err)
+	}
+}
+
+func TestDisplayName(t *testing.T) {
+	tests := map[string]string{
+		"/data/logs/app.log":       "app.log",
+		"json:1GiB":                "json:1GiB",
+		"tar:/srv/site":            "tar:/srv/site",
+		"concat:data/**/*.log,x.y": "concat:data/**/*.log,x.y",
+	}
+	for input, want := range tests {
+		if got := DisplayName(input); got != want {
+			t.Errorf("DisplayName(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package corpus
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TarDirectory writes the tree rooted at dir to w as a tar stream. Entries
+// are written in lexical order with owner names cleared, so the stream only
+// changes when the tree does. Files other than directories, regular files
+// and symlinks are skipped.
+func TarDirectory(w io.Writer, dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+
+	tw := tar.NewWriter(w)
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return writeEntry(tw, path, filepath.ToSlash(rel), info)
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// TarFiles writes files to w as a tar stream, one entry per file in the
+// given order. Entries are named by their path with any volume name and
+// leading separators or parent references removed.
+func TarFiles(w io.Writer, paths []string) error {
+	tw := tar.NewWriter(w)
+	for _, path := range paths {
+		info, err := os.Lstat(path)
+		if err != nil {
+			return err
+		}
+		if err := writeEntry(tw, path, entryName(path), info); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+// entryName turns a file path into a relative tar entry name
+func entryName(path string) string {
+	name := filepath.ToSlash(filepath.Clean(path[len(filepath.VolumeName(path)):]))
+	for {
+		trimmed := strings.TrimPrefix(strings.TrimPrefix(name, "/"), "../")
+		if trimmed == name {
+			return name
+		}
+		name = trimmed
+	}
+}
+
+// writeEntry writes the file at path to tw under name, with owner names
+// cleared. Files other than directories, regular files and symlinks are
+// skipped.
+func writeEntry(tw *tar.Writer, path, name string, info fs.FileInfo) error {
+	var link string
+	switch {
+	case info.Mode().IsRegular(), info.IsDir():
+	case info.Mode()&fs.ModeSymlink != 0:
+		var err error
+		if link, err = os.Readlink(path); err != nil {
+			return err
+		}
+	default:
+		return nil
+	}
+
+	hdr, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	hdr.Name = name
+	if info.IsDir() {
+		hdr.Name += "/"
+	}
+	hdr.Uid, hdr.Gid, hdr.Uname, hdr.Gname = 0, 0, "", ""
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+	return copyFile(tw, path)
+}
+
+func copyFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func(f *os.File) {
+		err := f.Close()
+		if err != nil {
+			fmt.Printf("warning: failed to close file: %v\n", err)
+		}
+	}(f)
+
+	_, err = io.Copy(w, f)
+	return err
+}
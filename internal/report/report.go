@@ -0,0 +1,278 @@
+// Package report renders benchmark results as a single self-contained HTML
+// file with inline SVG charts, so it can be shared without any other assets
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/aomarai/compstat/internal/benchmark"
+	"github.com/aomarai/compstat/internal/corpus"
+)
+
+// Options describes the report
+type Options struct {
+	// Title is the page title; empty means "compstat report"
+	Title string
+	// Sources lists the results files, shown in the header
+	Sources []string
+	// Generated is the generation time shown in the header
+	Generated time.Time
+}
+
+// Memory chart colors for compression and decompression
+const (
+	compressColor   = "#4c78a8"
+	decompressColor = "#f58518"
+)
+
+// page is the data of the report template
+type page struct {
+	Title     string
+	Sources   []string
+	Generated string
+	Results   int
+	Failed    int
+	Sections  []section
+}
+
+// section holds the charts and table of one input file
+type section struct {
+	Name   string
+	Legend []legendEntry
+	Charts []template.HTML
+	Rows   []tableRow
+}
+
+type legendEntry struct {
+	Name  string
+	Color string
+}
+
+// tableRow is one configuration in the sortable table
+type tableRow struct {
+	Algorithm string
+	Level     int
+	Params    string
+	Threads   string
+	Version   string
+	Runs      int
+	Ratio     float64
+	Comp      float64
+	Decomp    float64
+	CompRSS   float64
+	DecompRSS float64
+}
+
+// Write renders results as an HTML page with one section per input file.
+// Failed runs are counted but left out of charts and tables.
+func Write(w io.Writer, results []benchmark.Result, opts Options) error {
+	p := page{
+		Title:   opts.Title,
+		Sources: opts.Sources,
+		Results: len(results),
+	}
+	if p.Title == "" {
+		p.Title = "compstat report"
+	}
+	if !opts.Generated.IsZero() {
+		p.Generated = opts.Generated.Format(time.RFC1123)
+	}
+	for _, res := range results {
+		if !res.Succeeded() {
+			p.Failed++
+		}
+	}
+
+	summaries := benchmark.Aggregate(results)
+	colors := codecColors(summaries)
+	byFile := make(map[string][]benchmark.Summary)
+	files := make([]string, 0)
+	for _, s := range summaries {
+		if _, ok := byFile[s.FilePath]; !ok {
+			files = append(files, s.FilePath)
+		}
+		byFile[s.FilePath] = append(byFile[s.FilePath], s)
+	}
+	for _, file := range files {
+		p.Sections = append(p.Sections, newSection(corpus.DisplayName(file), byFile[file], colors))
+	}
+	return pageTemplate.Execute(w, p)
+}
+
+// codecColors assigns palette colors to algorithms in name order
+func codecColors(summaries []benchmark.Summary) map[string]string {
+	names := make([]string, 0)
+	colors := make(map[string]string)
+	for _, s := range summaries {
+		if _, ok := colors[s.Algorithm]; !ok {
+			colors[s.Algorithm] = ""
+			names = append(names, s.Algorithm)
+		}
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		colors[name] = palette[i%len(palette)]
+	}
+	return colors
+}
+
+// configLabel names a configuration within one input's section
+func configLabel(s benchmark.Summary, threads bool) string {
+	label := fmt.Sprintf("%s %d", s.Algorithm, s.Level)
+	if params := benchmark.ParamsLabel(s.Params, s.DictionaryBytes, s.RecordFormat); params != "-" {
+		label += " " + params
+	}
+	if threads {
+		label += fmt.Sprintf(" t=%d/%d", s.CompressThreads, s.DecompressThreads)
+	}
+	return label
+}
+
+// variantLabel names the level curve of a configuration: everything but
+// the level
+func variantLabel(s benchmark.Summary, threads bool) string {
+	label := s.Algorithm
+	if params := benchmark.ParamsLabel(s.Params, s.DictionaryBytes, s.RecordFormat); params != "-" {
+		label += " " + params
+	}
+	if threads {
+		label += fmt.Sprintf(" t=%d/%d", s.CompressThreads, s.DecompressThreads)
+	}
+	if s.CodecVersion != "" {
+		label += " " + s.CodecVersion
+	}
+	return label
+}
+
+// newSection builds the charts and table of one input file
+func newSection(name string, summaries []benchmark.Summary, colors map[string]string) section {
+	sec := section{Name: name}
+
+	// Thread counts only need labelling when they vary
+	threads := false
+	for _, s := range summaries {
+		if s.CompressThreads != summaries[0].CompressThreads || s.DecompressThreads != summaries[0].DecompressThreads {
+			threads = true
+		}
+	}
+
+	algorithms := make([]string, 0)
+	byAlgorithm := make(map[string][]point)
+	variants := make([]string, 0)
+	ratioCurves := make(map[string]*series)
+	speedCurves := make(map[string]*series)
+	var ratios, compSpeeds, levels []float64
+	for _, s := range summaries {
+		label := configLabel(s, threads)
+		pt := point{
+			x: s.CompressionSpeedMBs.Mean,
+			y: s.CompressionRatio.Mean,
+			label: fmt.Sprintf("%s: ratio %.4f, %.2f MB/s",
+				label, s.CompressionRatio.Mean, s.CompressionSpeedMBs.Mean),
+		}
+		if _, ok := byAlgorithm[s.Algorithm]; !ok {
+			algorithms = append(algorithms, s.Algorithm)
+		}
+		byAlgorithm[s.Algorithm] = append(byAlgorithm[s.Algorithm], pt)
+
+		variant := variantLabel(s, threads)
+		if _, ok := ratioCurves[variant]; !ok {
+			// Further variants of a codec are dashed
+			dashed := false
+			for _, other := range ratioCurves {
+				dashed = dashed || other.color == colors[s.Algorithm]
+			}
+			ratioCurves[variant] = &series{name: variant, color: colors[s.Algorithm], dashed: dashed}
+			speedCurves[variant] = &series{name: variant, color: colors[s.Algorithm], dashed: dashed}
+			variants = append(variants, variant)
+		}
+		level := float64(s.Level)
+		ratioCurves[variant].points = append(ratioCurves[variant].points, point{x: level, y: s.CompressionRatio.Mean, label: pt.label})
+		speedCurves[variant].points = append(speedCurves[variant].points, point{x: level, y: s.CompressionSpeedMBs.Mean, label: pt.label})
+
+		ratios = append(ratios, s.CompressionRatio.Mean)
+		compSpeeds = append(compSpeeds, s.CompressionSpeedMBs.Mean)
+		levels = append(levels, level)
+
+		sec.Rows = append(sec.Rows, tableRow{
+			Algorithm: s.Algorithm,
+			Level:     s.Level,
+			Params:    benchmark.ParamsLabel(s.Params, s.DictionaryBytes, s.RecordFormat),
+			Threads:   fmt.Sprintf("%d/%d", s.CompressThreads, s.DecompressThreads),
+			Version:   s.CodecVersion,
+			Runs:      s.Runs,
+			Ratio:     s.CompressionRatio.Mean,
+			Comp:      s.CompressionSpeedMBs.Mean,
+			Decomp:    s.DecompressionSpeedMBs.Mean,
+			CompRSS:   s.CompressionMaxRSSMB.Mean,
+			DecompRSS: s.DecompressionMaxRSSMB.Mean,
+		})
+	}
+
+	sort.Strings(algorithms)
+	scatter := make([]series, 0, len(algorithms))
+	for _, a := range algorithms {
+		sec.Legend = append(sec.Legend, legendEntry{Name: a, Color: colors[a]})
+		scatter = append(scatter, series{name: a, color: colors[a], points: byAlgorithm[a]})
+	}
+	sec.Charts = append(sec.Charts, plot("Ratio vs. compression speed",
+		newAxis(compSpeeds, true, false, "Compression speed (MB/s, log scale)"),
+		newAxis(ratios, false, false, "Compression ratio (lower is better)"), scatter, false))
+
+	// Level curves: one line per codec variant. Aggregate sorts summaries
+	// by level within a variant, so the points are already in order.
+	ratioLines := make([]series, 0, len(variants))
+	speedLines := make([]series, 0, len(variants))
+	for _, v := range variants {
+		ratioLines = append(ratioLines, *ratioCurves[v])
+		speedLines = append(speedLines, *speedCurves[v])
+	}
+	levelAxis := newAxis(levels, false, false, "Level")
+	levelAxis.integer = true
+	sec.Charts = append(sec.Charts,
+		plot("Ratio by level", levelAxis, newAxis(ratios, false, false, "Compression ratio"), ratioLines, true),
+		plot("Compression speed by level", levelAxis, newAxis(compSpeeds, true, false, "Compression speed (MB/s, log scale)"), speedLines, true))
+
+	// Decompression speed, fastest first
+	decomp := make([]benchmark.Summary, 0, len(summaries))
+	for _, s := range summaries {
+		if s.DecompressionSpeedMBs.N > 0 {
+			decomp = append(decomp, s)
+		}
+	}
+	sort.SliceStable(decomp, func(i, j int) bool {
+		return decomp[i].DecompressionSpeedMBs.Mean > decomp[j].DecompressionSpeedMBs.Mean
+	})
+	if len(decomp) > 0 {
+		bars := make([]bar, 0, len(decomp))
+		for _, s := range decomp {
+			bars = append(bars, bar{
+				label:  configLabel(s, threads),
+				values: []float64{s.DecompressionSpeedMBs.Mean},
+				colors: []string{colors[s.Algorithm]},
+			})
+		}
+		sec.Charts = append(sec.Charts, barChart("Decompression speed", "Decompression speed (MB/s)", bars))
+	}
+
+	// Peak memory, skipped when the platform reported none
+	memory := make([]bar, 0, len(summaries))
+	measured := false
+	for _, s := range summaries {
+		comp, decomp := s.CompressionMaxRSSMB.Mean, s.DecompressionMaxRSSMB.Mean
+		measured = measured || comp > 0 || decomp > 0
+		memory = append(memory, bar{
+			label:  configLabel(s, threads),
+			values: []float64{comp, decomp},
+			colors: []string{compressColor, decompressColor},
+		})
+	}
+	if measured {
+		sec.Charts = append(sec.Charts, barChart("Peak memory: compression (blue) and decompression (orange)", "Max RSS (MB)", memory))
+	}
+	return sec
+}
@@ -0,0 +1,104 @@
+package report
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aomarai/compstat/internal/benchmark"
+)
+
+func result(file, algorithm string, level int, ratio, comp, decomp, rss float64) benchmark.Result {
+	return benchmark.Result{
+		FilePath:              file,
+		Algorithm:             algorithm,
+		Level:                 level,
+		CompressThreads:       1,
+		DecompressThreads:     1,
+		CompressionRatio:      ratio,
+		CompressionSpeedMBs:   comp,
+		DecompressionSpeedMBs: decomp,
+		DecompressionTimeS:    1,
+		CompressionMaxRSSMB:   rss,
+		DecompressionMaxRSSMB: rss / 2,
+		Status:                benchmark.StatusOK,
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	results := []benchmark.Result{
+		result("/data/<logs>&.txt", "zstd", 1, 0.30, 400, 1200, 20),
+		result("/data/<logs>&.txt", "zstd", 19, 0.22, 5, 1100, 90),
+		result("/data/<logs>&.txt", "xz", 6, 0.20, 2, 90, 95),
+		result("/data/other.bin", "zstd", 1, 0.99, 600, 3000, 10),
+		{FilePath: "/data/other.bin", Algorithm: "xz", Status: benchmark.StatusFailed},
+	}
+
+	var buf bytes.Buffer
+	err := Write(&buf, results, Options{Sources: []string{"results.csv"}, Generated: time.Unix(0, 0)})
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	html := buf.String()
+
+	// Two sections of scatter, two level curves, decompression and memory
+	if n := strings.Count(html, "<svg"); n != 10 {
+		t.Errorf("Expected 10 charts, got %d", n)
+	}
+	if strings.Contains(html, "<logs>") || !strings.Contains(html, "&lt;logs&gt;&amp;.txt") {
+		t.Error("Input names are not escaped")
+	}
+	for _, external := range []string{"http://", "https://", "src=", "<link"} {
+		if strings.Contains(html, external) {
+			t.Errorf("Report references an external asset: %q", external)
+		}
+	}
+	if !strings.Contains(html, `class="sortable"`) || strings.Count(html, "<tr>") != 2+4 {
+		t.Error("Expected a sortable table per section with one row per configuration")
+	}
+	if !strings.Contains(html, "1 failed and excluded") {
+		t.Error("Failed runs are not reported")
+	}
+}
+
+func TestWriteReportSkipsUnmeasuredMemory(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, []benchmark.Result{result("in", "zstd", 3, 0.3, 100, 500, 0)}, Options{}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "Peak memory") {
+		t.Error("Expected no memory chart without RSS measurements")
+	}
+	if !strings.Contains(buf.String(), "<title>compstat report</title>") {
+		t.Error("Expected the default title")
+	}
+}
+
+func TestAxisTicks(t *testing.T) {
+	log := newAxis([]float64{3, 450, 0}, true, false, "")
+	if log.lo != 1 || log.hi != 1000 {
+		t.Errorf("Log axis spans %g..%g, want 1..1000", log.lo, log.hi)
+	}
+	if ticks := log.ticks(); len(ticks) != 4 || ticks[3] != 1000 {
+		t.Errorf("Log ticks = %v", ticks)
+	}
+
+	levels := newAxis([]float64{-1, 3}, false, false, "")
+	levels.integer = true
+	for _, tick := range levels.ticks() {
+		if tick != float64(int(tick)) {
+			t.Errorf("Level ticks = %v, want whole numbers", levels.ticks())
+			break
+		}
+	}
+	if got := formatTick(math.Copysign(0, -1)); got != "0" {
+		t.Errorf("formatTick(-0) = %q", got)
+	}
+	for raw, want := range map[float64]float64{0.03: 0.05, 0.7: 1, 13: 20, 180: 200} {
+		if got := niceStep(raw); got != want {
+			t.Errorf("niceStep(%g) = %g, want %g", raw, got, want)
+		}
+	}
+}
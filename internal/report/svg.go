@@ -0,0 +1,285 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Chart geometry in SVG user units
+const (
+	chartWidth   = 640
+	chartHeight  = 360
+	marginLeft   = 70
+	marginRight  = 20
+	marginTop    = 30
+	marginBottom = 50
+
+	barHeight     = 16
+	barGap        = 6
+	barLabelWidth = 220
+)
+
+// palette holds the series colors, assigned to codecs in name order
+var palette = []string{
+	"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd",
+	"#8c564b", "#e377c2", "#7f7f7f", "#bcbd22", "#17becf",
+}
+
+// axis maps data values onto a range of pixels
+type axis struct {
+	lo, hi float64
+	log    bool
+	// integer limits linear ticks to whole numbers, for levels
+	integer bool
+	label   string
+}
+
+// newAxis returns an axis covering values. Log axes ignore values that are
+// not positive and span whole decades; linear axes start at zero when zero
+// is true.
+func newAxis(values []float64, log, zero bool, label string) axis {
+	a := axis{lo: math.Inf(1), hi: math.Inf(-1), log: log, label: label}
+	if zero {
+		a.lo = 0
+	}
+	for _, v := range values {
+		if log && v <= 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		a.lo = math.Min(a.lo, v)
+		a.hi = math.Max(a.hi, v)
+	}
+	switch {
+	case math.IsInf(a.lo, 1):
+		a.lo, a.hi = 0, 1
+		if log {
+			a.lo, a.hi = 1, 10
+		}
+	case log:
+		a.lo = math.Pow(10, math.Floor(math.Log10(a.lo)))
+		a.hi = math.Pow(10, math.Ceil(math.Log10(a.hi)))
+		if a.lo == a.hi {
+			a.hi *= 10
+		}
+	case a.lo == a.hi:
+		pad := math.Max(math.Abs(a.lo)*0.1, 1e-3)
+		a.lo, a.hi = a.lo-pad, a.hi+pad
+	default:
+		pad := (a.hi - a.lo) * 0.05
+		if !zero {
+			a.lo -= pad
+		}
+		a.hi += pad
+	}
+	return a
+}
+
+// valid reports whether v can be drawn on the axis
+func (a axis) valid(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0) && (!a.log || v > 0)
+}
+
+// pos maps v onto the pixel range [from, to]
+func (a axis) pos(v, from, to float64) float64 {
+	var f float64
+	if a.log {
+		f = (math.Log10(v) - math.Log10(a.lo)) / (math.Log10(a.hi) - math.Log10(a.lo))
+	} else {
+		f = (v - a.lo) / (a.hi - a.lo)
+	}
+	return from + f*(to-from)
+}
+
+// ticks returns the values to label on the axis
+func (a axis) ticks() []float64 {
+	ticks := make([]float64, 0)
+	if a.log {
+		decades := math.Log10(a.hi / a.lo)
+		for d := a.lo; d <= a.hi*1.0001; d *= 10 {
+			ticks = append(ticks, d)
+			if decades <= 2 && d*2 < a.hi {
+				ticks = append(ticks, d*2, d*5)
+			}
+		}
+		return ticks
+	}
+	step := niceStep((a.hi - a.lo) / 5)
+	if a.integer {
+		step = math.Max(step, 1)
+	}
+	for v := math.Ceil(a.lo/step) * step; v <= a.hi+step*1e-9; v += step {
+		ticks = append(ticks, v)
+	}
+	return ticks
+}
+
+// niceStep rounds a tick interval up to 1, 2 or 5 times a power of ten
+func niceStep(raw float64) float64 {
+	if raw <= 0 {
+		return 1
+	}
+	exp := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, m := range []float64{1, 2, 5} {
+		if raw <= m*exp {
+			return m * exp
+		}
+	}
+	return 10 * exp
+}
+
+// formatTick formats a tick value compactly
+func formatTick(v float64) string {
+	if v == 0 {
+		// Also covers negative zero
+		return "0"
+	}
+	if math.Abs(v) < 0.01 {
+		return strconv.FormatFloat(v, 'g', 2, 64)
+	}
+	return strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+}
+
+// point is one data point; label is shown as a tooltip
+type point struct {
+	x, y  float64
+	label string
+}
+
+// series is a set of points drawn in one color, as markers or as a line
+type series struct {
+	name   string
+	color  string
+	dashed bool
+	points []point
+}
+
+// svgWriter accumulates SVG markup
+type svgWriter struct {
+	strings.Builder
+}
+
+func (w *svgWriter) printf(format string, args ...any) {
+	_, _ = fmt.Fprintf(w, format, args...)
+}
+
+// text writes an escaped text element
+func (w *svgWriter) text(x, y float64, anchor, class, s string, extra string) {
+	w.printf(`<text x="%.1f" y="%.1f" text-anchor="%s" class="%s"%s>%s</text>`,
+		x, y, anchor, class, extra, template.HTMLEscapeString(s))
+}
+
+// frame draws the axes, grid lines and labels of a plot
+func (w *svgWriter) frame(x, y axis) {
+	left, right := float64(marginLeft), float64(chartWidth-marginRight)
+	top, bottom := float64(marginTop), float64(chartHeight-marginBottom)
+	for _, t := range x.ticks() {
+		px := x.pos(t, left, right)
+		w.printf(`<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" class="grid"/>`, px, top, px, bottom)
+		w.text(px, bottom+16, "middle", "tick", formatTick(t), "")
+	}
+	for _, t := range y.ticks() {
+		py := y.pos(t, bottom, top)
+		w.printf(`<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" class="grid"/>`, left, py, right, py)
+		w.text(left-6, py+4, "end", "tick", formatTick(t), "")
+	}
+	w.printf(`<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" class="frame"/>`, left, top, right-left, bottom-top)
+	w.text((left+right)/2, chartHeight-10, "middle", "axis", x.label, "")
+	w.text(16, (top+bottom)/2, "middle", "axis", y.label, fmt.Sprintf(` transform="rotate(-90 16 %.1f)"`, (top+bottom)/2))
+}
+
+// open starts an SVG element with a title
+func (w *svgWriter) open(width, height float64, title string) {
+	w.printf(`<svg viewBox="0 0 %.0f %.0f" width="%.0f" height="%.0f" role="img">`, width, height, width, height)
+	w.printf(`<title>%s</title>`, template.HTMLEscapeString(title))
+	w.text(width/2, 18, "middle", "title", title, "")
+}
+
+func (w *svgWriter) close() template.HTML {
+	w.WriteString("</svg>")
+	// Every string in the markup was escaped as it was written
+	return template.HTML(w.String())
+}
+
+// plot draws series on x and y axes: as connected lines when lines is true,
+// as markers otherwise
+func plot(title string, x, y axis, all []series, lines bool) template.HTML {
+	var w svgWriter
+	w.open(chartWidth, chartHeight, title)
+	w.frame(x, y)
+	left, right := float64(marginLeft), float64(chartWidth-marginRight)
+	top, bottom := float64(marginTop), float64(chartHeight-marginBottom)
+	radius := 5
+	if lines {
+		radius = 3
+	}
+	for _, s := range all {
+		coords := make([]string, 0, len(s.points))
+		for _, p := range s.points {
+			if !x.valid(p.x) || !y.valid(p.y) {
+				continue
+			}
+			px, py := x.pos(p.x, left, right), y.pos(p.y, bottom, top)
+			coords = append(coords, fmt.Sprintf("%.1f,%.1f", px, py))
+			w.printf(`<circle cx="%.1f" cy="%.1f" r="%d" fill="%s"><title>%s</title></circle>`,
+				px, py, radius, s.color, template.HTMLEscapeString(p.label))
+		}
+		if lines && len(coords) > 1 {
+			dash := ""
+			if s.dashed {
+				dash = ` stroke-dasharray="6 4"`
+			}
+			w.printf(`<polyline points="%s" fill="none" stroke="%s" stroke-width="2"%s><title>%s</title></polyline>`,
+				strings.Join(coords, " "), s.color, dash, template.HTMLEscapeString(s.name))
+		}
+	}
+	return w.close()
+}
+
+// bar is one row of a bar chart, with a value and color per series
+type bar struct {
+	label  string
+	values []float64
+	colors []string
+}
+
+// barChart draws horizontal bars, one group per bar with one bar per value
+func barChart(title, xLabel string, bars []bar) template.HTML {
+	perGroup := 1
+	values := make([]float64, 0, len(bars))
+	for _, b := range bars {
+		perGroup = max(perGroup, len(b.values))
+		values = append(values, b.values...)
+	}
+	x := newAxis(values, false, true, xLabel)
+	groupHeight := float64(perGroup*barHeight + barGap)
+	left, right := float64(barLabelWidth), float64(chartWidth-marginRight)
+	top := float64(marginTop)
+	bottom := top + groupHeight*float64(len(bars))
+	height := bottom + 40
+
+	var w svgWriter
+	w.open(chartWidth, height, title)
+	for _, t := range x.ticks() {
+		px := x.pos(t, left, right)
+		w.printf(`<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" class="grid"/>`, px, top, px, bottom)
+		w.text(px, bottom+16, "middle", "tick", formatTick(t), "")
+	}
+	for i, b := range bars {
+		y := top + groupHeight*float64(i)
+		w.text(left-6, y+float64(len(b.values)*barHeight)/2+4, "end", "tick", b.label, "")
+		for j, v := range b.values {
+			if !x.valid(v) || v < 0 {
+				continue
+			}
+			w.printf(`<rect x="%.1f" y="%.1f" width="%.1f" height="%d" fill="%s"><title>%s: %s</title></rect>`,
+				left, y+float64(j*barHeight), x.pos(v, left, right)-left, barHeight-2, b.colors[j],
+				template.HTMLEscapeString(b.label), formatTick(v))
+		}
+	}
+	w.printf(`<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" class="axisline"/>`, left, top, left, bottom)
+	w.text((left+right)/2, height-8, "middle", "axis", xLabel, "")
+	return w.close()
+}
@@ -0,0 +1,85 @@
+package report
+
+import "html/template"
+
+// pageTemplate renders the report. Styles and the table sorting script are
+// inline so the file has no external assets.
+var pageTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2em; color: #222; }
+h1 { margin-bottom: 0.2em; }
+.meta { color: #666; margin-bottom: 2em; }
+section { margin-bottom: 3em; }
+.legend span { display: inline-block; margin-right: 1.2em; }
+.legend i { display: inline-block; width: 0.9em; height: 0.9em; margin-right: 0.3em; vertical-align: middle; border-radius: 50%; }
+.charts { display: flex; flex-wrap: wrap; gap: 1em; margin: 1em 0; }
+.charts svg { border: 1px solid #ddd; background: #fff; max-width: 100%; height: auto; }
+svg text { font-size: 11px; fill: #333; }
+svg text.title { font-size: 14px; font-weight: bold; }
+svg text.axis { font-size: 12px; }
+svg .grid { stroke: #eee; }
+svg .frame { fill: none; stroke: #999; }
+svg .axisline { stroke: #999; }
+svg circle { fill-opacity: 0.8; }
+table { border-collapse: collapse; font-size: 13px; }
+th, td { padding: 0.3em 0.7em; border-bottom: 1px solid #ddd; text-align: right; }
+th:nth-child(-n+5), td:nth-child(-n+5) { text-align: left; }
+th { cursor: pointer; background: #f4f4f4; user-select: none; }
+th.asc::after { content: " \25B2"; }
+th.desc::after { content: " \25BC"; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<div class="meta">
+{{- if .Generated}}Generated {{.Generated}}. {{end -}}
+{{.Results}} run(s){{if .Failed}}, {{.Failed}} failed and excluded{{end}}.
+{{- range $i, $s := .Sources}}{{if eq $i 0}} Results: {{else}}, {{end}}{{$s}}{{end}}
+</div>
+{{range .Sections}}
+<section>
+<h2>{{.Name}}</h2>
+<div class="legend">{{range .Legend}}<span><i style="background: {{.Color}}"></i>{{.Name}}</span>{{end}}</div>
+<div class="charts">{{range .Charts}}
+{{.}}{{end}}
+</div>
+<table class="sortable">
+<thead><tr><th>Algorithm</th><th>Level</th><th>Params</th><th>Threads</th><th>Version</th><th>Runs</th><th>Ratio</th><th>Comp MB/s</th><th>Decomp MB/s</th><th>Comp RSS MB</th><th>Decomp RSS MB</th></tr></thead>
+<tbody>{{range .Rows}}
+<tr><td>{{.Algorithm}}</td><td data-v="{{.Level}}">{{.Level}}</td><td>{{.Params}}</td><td>{{.Threads}}</td><td>{{.Version}}</td><td data-v="{{.Runs}}">{{.Runs}}</td><td data-v="{{.Ratio}}">{{printf "%.4f" .Ratio}}</td><td data-v="{{.Comp}}">{{printf "%.2f" .Comp}}</td><td data-v="{{.Decomp}}">{{printf "%.2f" .Decomp}}</td><td data-v="{{.CompRSS}}">{{printf "%.1f" .CompRSS}}</td><td data-v="{{.DecompRSS}}">{{printf "%.1f" .DecompRSS}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+</section>
+{{else}}
+<p>No successful runs.</p>
+{{end}}
+<script>
+document.querySelectorAll("table.sortable").forEach(function (table) {
+  var headers = table.querySelectorAll("th");
+  headers.forEach(function (th, col) {
+    th.addEventListener("click", function () {
+      var asc = !th.classList.contains("asc");
+      headers.forEach(function (h) { h.classList.remove("asc", "desc"); });
+      th.classList.add(asc ? "asc" : "desc");
+      var body = table.tBodies[0];
+      var rows = Array.prototype.slice.call(body.rows);
+      rows.sort(function (a, b) {
+        var x = a.cells[col], y = b.cells[col];
+        var c = x.dataset.v !== undefined
+          ? parseFloat(x.dataset.v) - parseFloat(y.dataset.v)
+          : x.textContent.localeCompare(y.textContent);
+        return asc ? c : -c;
+      });
+      rows.forEach(function (r) { body.appendChild(r); });
+    });
+  });
+});
+</script>
+</body>
+</html>
+`))
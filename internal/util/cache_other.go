@@ -0,0 +1,13 @@
+//go:build !linux
+
+package util
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// DropFileCache is not supported on this platform
+func DropFileCache(path string) error {
+	return fmt.Errorf("dropping the page cache is not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,100 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// DecodeConfigFile decodes a YAML, TOML or JSON file into v, choosing the
+// format from the extension. Unknown fields are rejected.
+func DecodeConfigFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(v)
+	case ".toml":
+		var md toml.MetaData
+		md, err = toml.Decode(string(data), v)
+		if err == nil && len(md.Undecoded()) > 0 {
+			err = fmt.Errorf("unknown field %s", md.Undecoded()[0])
+		}
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(v)
+	default:
+		return fmt.Errorf("unsupported format %q (use .yaml, .toml or .json)", filepath.Ext(path))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// Spec is a comma-separated list such as "1..19" or "27,31". In config files
+// it may also be written as a single number or as an array.
+type Spec string
+
+func (s *Spec) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	return s.set(v)
+}
+
+func (s *Spec) UnmarshalYAML(node *yaml.Node) error {
+	var v interface{}
+	if err := node.Decode(&v); err != nil {
+		return err
+	}
+	return s.set(v)
+}
+
+func (s *Spec) UnmarshalTOML(v interface{}) error {
+	return s.set(v)
+}
+
+func (s *Spec) set(v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		*s = ""
+	case string:
+		*s = Spec(v)
+	case int:
+		*s = Spec(strconv.Itoa(v))
+	case int64:
+		*s = Spec(strconv.FormatInt(v, 10))
+	case float64:
+		*s = Spec(strconv.FormatFloat(v, 'f', -1, 64))
+	case bool:
+		*s = Spec(strconv.FormatBool(v))
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			var part Spec
+			if err := part.set(item); err != nil {
+				return err
+			}
+			parts[i] = string(part)
+		}
+		*s = Spec(strings.Join(parts, ","))
+	default:
+		return fmt.Errorf("expected a string, number, boolean or list, got %T", v)
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package util
+
+import "os/exec"
+
+// killGroupOnCancel keeps the default behavior of killing only the direct
+// child, since process groups are not available on this platform
+func killGroupOnCancel(cmd *exec.Cmd) {}
@@ -0,0 +1,124 @@
+package util
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseIntList parses a comma-separated list of integers and ranges into a
+// sorted list of unique values. Supported forms are:
+//
+//	4          a single value
+//	1..8       every value from 1 to 8
+//	0..20:5    from 0 to 20 in steps of 5 (also written 0..20:+5)
+//	1..32:x2   from 1 to 32, multiplying by 2 each step
+func ParseIntList(spec string) ([]int, error) {
+	seen := make(map[int]bool)
+	values := make([]int, 0)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		expanded, err := parseIntRange(part)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range expanded {
+			if !seen[v] {
+				seen[v] = true
+				values = append(values, v)
+			}
+		}
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("empty list %q", spec)
+	}
+	sort.Ints(values)
+	return values, nil
+}
+
+// ParseThreads parses a list of thread counts with ParseIntList. An empty
+// spec or "0" means the number of CPUs.
+func ParseThreads(spec string) ([]int, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" || spec == "0" {
+		return []int{runtime.NumCPU()}, nil
+	}
+	threads, err := ParseIntList(spec)
+	if err != nil {
+		return nil, err
+	}
+	if threads[0] < 1 {
+		return nil, fmt.Errorf("thread counts must be at least 1")
+	}
+	return threads, nil
+}
+
+// MaxRangeValues limits how many values a single range may expand to
+const MaxRangeValues = 10000
+
+func parseIntRange(part string) ([]int, error) {
+	lo, rest, isRange := strings.Cut(part, "..")
+	if !isRange {
+		v, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q", part)
+		}
+		return []int{v}, nil
+	}
+
+	hi, step, hasStep := strings.Cut(rest, ":")
+	start, err := strconv.Atoi(strings.TrimSpace(lo))
+	if err != nil {
+		return nil, fmt.Errorf("invalid range start in %q", part)
+	}
+	end, err := strconv.Atoi(strings.TrimSpace(hi))
+	if err != nil {
+		return nil, fmt.Errorf("invalid range end in %q", part)
+	}
+	if end < start {
+		return nil, fmt.Errorf("range %q ends before it starts", part)
+	}
+
+	n := 1
+	if hasStep {
+		step = strings.TrimSpace(step)
+		if factor, ok := strings.CutPrefix(step, "x"); ok {
+			f, err := strconv.Atoi(factor)
+			if err != nil || f < 2 {
+				return nil, fmt.Errorf("invalid multiplier in %q", part)
+			}
+			if start <= 0 {
+				return nil, fmt.Errorf("multiplicative range %q must start above 0", part)
+			}
+			// Stop before the next value would pass end, so v*f never
+			// overflows; at most 63 values fit in an int
+			values := make([]int, 0)
+			for v := start; ; v *= f {
+				values = append(values, v)
+				if v > end/f {
+					return values, nil
+				}
+			}
+		}
+		n, err = strconv.Atoi(strings.TrimPrefix(step, "+"))
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid step in %q", part)
+		}
+	}
+
+	// The distance between start and end always fits in a uint64
+	count := (uint64(end)-uint64(start))/uint64(n) + 1
+	if count > MaxRangeValues {
+		return nil, fmt.Errorf("range %q expands to %d values, more than the limit of %d", part, count, MaxRangeValues)
+	}
+	values := make([]int, count)
+	for i := range values {
+		values[i] = start + i*n
+	}
+	return values, nil
+}
@@ -0,0 +1,80 @@
+package util
+
+import (
+	"math"
+	"runtime"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestParseIntList(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected []int
+	}{
+		{"4", []int{4}},
+		{"1,2,4,8,16", []int{1, 2, 4, 8, 16}},
+		{"1..4", []int{1, 2, 3, 4}},
+		{"1..32:x2", []int{1, 2, 4, 8, 16, 32}},
+		{"1..20:x3", []int{1, 3, 9}},
+		{"0..20:5", []int{0, 5, 10, 15, 20}},
+		{"0..10:+4", []int{0, 4, 8}},
+		{"-5..-3,1", []int{-5, -4, -3, 1}},
+		{"8, 1..3, 2", []int{1, 2, 3, 8}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseIntList(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseIntList(%q) failed: %v", tt.spec, err)
+			}
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("ParseIntList(%q) = %v, expected %v", tt.spec, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseIntListErrors(t *testing.T) {
+	full := strconv.Itoa(math.MinInt) + ".." + strconv.Itoa(math.MaxInt)
+	for _, spec := range []string{"", "a", "4..1", "1..8:x1", "0..8:x2", "1..8:0", "1..b", "1..100000", full} {
+		if _, err := ParseIntList(spec); err == nil {
+			t.Errorf("Expected error for %q", spec)
+		}
+	}
+}
+
+func TestParseIntListNearLimits(t *testing.T) {
+	values, err := ParseIntList("1.." + strconv.Itoa(math.MaxInt) + ":x2")
+	if err != nil {
+		t.Fatalf("ParseIntList failed: %v", err)
+	}
+	if want := strconv.IntSize - 1; len(values) != want || values[want-1] != 1<<(want-1) {
+		t.Errorf("Expected the %d powers of two, got %d values ending in %d", want, len(values), values[len(values)-1])
+	}
+
+	values, err = ParseIntList(strconv.Itoa(math.MaxInt-7) + ".." + strconv.Itoa(math.MaxInt) + ":+5")
+	if err != nil {
+		t.Fatalf("ParseIntList failed: %v", err)
+	}
+	if len(values) != 2 || values[1] != math.MaxInt-2 {
+		t.Errorf("Expected no overflow past the end, got %v", values)
+	}
+}
+
+func TestParseThreads(t *testing.T) {
+	for _, spec := range []string{"", "0", " "} {
+		got, err := ParseThreads(spec)
+		if err != nil || len(got) != 1 || got[0] != runtime.NumCPU() {
+			t.Errorf("ParseThreads(%q) = %v, %v; want CPU count", spec, got, err)
+		}
+	}
+	if got, err := ParseThreads("1..8:x2"); err != nil || !slices.Equal(got, []int{1, 2, 4, 8}) {
+		t.Errorf("ParseThreads(1..8:x2) = %v, %v", got, err)
+	}
+	if _, err := ParseThreads("0,4"); err == nil {
+		t.Error("Expected error for a zero thread count in a list")
+	}
+}
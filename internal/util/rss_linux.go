@@ -0,0 +1,88 @@
+//go:build linux
+
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// rssSampleInterval is how often a running child's peak RSS is sampled
+const rssSampleInterval = time.Millisecond
+
+// rssWatcher samples the VmHWM of a running child from /proc. The kernel seeds
+// a child's ru_maxrss with compstat's own peak when it execs from compstat's
+// address space, so the sampled value is the only one that belongs to the
+// child alone.
+type rssWatcher struct {
+	stop chan struct{}
+	done chan struct{}
+	peak int64
+}
+
+// watchRSS starts sampling the peak RSS of the process with the given pid
+func watchRSS(pid int) *rssWatcher {
+	w := &rssWatcher{stop: make(chan struct{}), done: make(chan struct{})}
+	path := fmt.Sprintf("/proc/%d/status", pid)
+	go func() {
+		defer close(w.done)
+		ticker := time.NewTicker(rssSampleInterval)
+		defer ticker.Stop()
+		for {
+			// VmHWM disappears once the child exits, so a failed read keeps
+			// the last sample
+			if hwm, ok := readVmHWM(path); ok && hwm > w.peak {
+				w.peak = hwm
+			}
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return w
+}
+
+// childPeakRSS stops the watcher and returns the child's own peak RSS given
+// the ru_maxrss the kernel reported for it. A reported value above compstat's
+// own peak cannot have been inherited, so it is exact; otherwise the sampled
+// peak is used. A child that exited before it could be sampled keeps the
+// reported value.
+func (w *rssWatcher) childPeakRSS(reported int64) int64 {
+	close(w.stop)
+	<-w.done
+	if reported > selfUsage().MaxRSSBytes || w.peak == 0 {
+		return reported
+	}
+	return w.peak
+}
+
+// readVmHWM returns the VmHWM field of a /proc/<pid>/status file in bytes
+func readVmHWM(path string) (int64, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := sc.Bytes()
+		if !bytes.HasPrefix(line, []byte("VmHWM:")) {
+			continue
+		}
+		fields := bytes.Fields(line[len("VmHWM:"):])
+		if len(fields) != 2 || string(fields[1]) != "kB" {
+			return 0, false
+		}
+		kb, err := strconv.ParseInt(string(fields[0]), 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return kb * 1024, true
+	}
+	return 0, false
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+
+package util
+
+// rssWatcher is a no-op on platforms without /proc/<pid>/status
+type rssWatcher struct{}
+
+// watchRSS returns a watcher that samples nothing
+func watchRSS(pid int) *rssWatcher {
+	return &rssWatcher{}
+}
+
+// childPeakRSS returns the peak RSS the kernel reported for the child
+func (w *rssWatcher) childPeakRSS(reported int64) int64 {
+	return reported
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package util
+
+import "os"
+
+// collectRusage is not supported on this platform and leaves m untouched
+func collectRusage(state *os.ProcessState, m *Measurement) {}
+
+// selfUsage is not supported on this platform and returns a zero Measurement
+func selfUsage() Measurement {
+	return Measurement{}
+}
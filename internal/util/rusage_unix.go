@@ -0,0 +1,45 @@
+//go:build unix
+
+package util
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+	"time"
+)
+
+// collectRusage fills in the rusage-derived fields of m for an exited child process
+func collectRusage(state *os.ProcessState, m *Measurement) {
+	ru, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok || ru == nil {
+		return
+	}
+	fillFromRusage(ru, m)
+}
+
+// selfUsage returns the resource usage of the compstat process so far
+func selfUsage() Measurement {
+	var ru syscall.Rusage
+	var m Measurement
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &ru); err != nil {
+		return m
+	}
+	m.User = time.Duration(ru.Utime.Nano())
+	m.System = time.Duration(ru.Stime.Nano())
+	fillFromRusage(&ru, &m)
+	return m
+}
+
+func fillFromRusage(ru *syscall.Rusage, m *Measurement) {
+	// ru_maxrss is reported in bytes on Darwin and in kilobytes elsewhere
+	if runtime.GOOS == "darwin" || runtime.GOOS == "ios" {
+		m.MaxRSSBytes = int64(ru.Maxrss)
+	} else {
+		m.MaxRSSBytes = int64(ru.Maxrss) * 1024
+	}
+	m.VoluntaryCtxSwitches = int64(ru.Nvcsw)
+	m.InvoluntaryCtxSwitches = int64(ru.Nivcsw)
+	m.MinorPageFaults = int64(ru.Minflt)
+	m.MajorPageFaults = int64(ru.Majflt)
+}
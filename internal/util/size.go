@@ -0,0 +1,81 @@
+package util
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// sizeUnits maps size suffixes to their multipliers. Decimal (KB, MB, GB)
+// and binary (KiB, MiB, GiB) units are both accepted; K, M and G are binary.
+var sizeUnits = []struct {
+	suffix string
+	factor int64
+}{
+	{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30}, {"TiB", 1 << 40},
+	{"KB", 1e3}, {"MB", 1e6}, {"GB", 1e9}, {"TB", 1e12},
+	{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}, {"T", 1 << 40},
+	{"B", 1},
+}
+
+// ParseSize parses a byte count such as "4096", "64KiB", "1.5GB" or "256M"
+func ParseSize(spec string) (int64, error) {
+	spec = strings.TrimSpace(spec)
+	number, factor := spec, int64(1)
+	for _, unit := range sizeUnits {
+		if len(spec) > len(unit.suffix) && strings.EqualFold(spec[len(spec)-len(unit.suffix):], unit.suffix) {
+			number, factor = strings.TrimSpace(spec[:len(spec)-len(unit.suffix)]), unit.factor
+			break
+		}
+	}
+	v, err := strconv.ParseFloat(number, 64)
+	if err != nil || v < 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("invalid size %q", spec)
+	}
+	// float64(math.MaxInt64) rounds up to 2^63, which no longer fits
+	n := v * float64(factor)
+	if n >= float64(math.MaxInt64) {
+		return 0, fmt.Errorf("size %q is too large", spec)
+	}
+	return int64(n), nil
+}
+
+// FormatSize formats a byte count with the largest binary unit that divides
+// it exactly, so that ParseSize(FormatSize(n)) == n
+func FormatSize(n int64) string {
+	for _, unit := range []struct {
+		suffix string
+		factor int64
+	}{{"TiB", 1 << 40}, {"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10}} {
+		if n >= unit.factor && n%unit.factor == 0 {
+			return strconv.FormatInt(n/unit.factor, 10) + unit.suffix
+		}
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+// ParseSizes parses a comma-separated list of sizes, e.g. "0,16KiB,110KiB"
+func ParseSizes(list string) ([]int64, error) {
+	sizes := make([]int64, 0)
+	for _, part := range strings.Split(list, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		n, err := ParseSize(part)
+		if err != nil {
+			return nil, err
+		}
+		sizes = append(sizes, n)
+	}
+	return sizes, nil
+}
+
+// FormatSizes is the inverse of ParseSizes
+func FormatSizes(sizes []int64) string {
+	parts := make([]string, len(sizes))
+	for i, n := range sizes {
+		parts[i] = FormatSize(n)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,62 @@
+package util
+
+import "testing"
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{
+		"4096":   4096,
+		"64KiB":  64 << 10,
+		"64kib":  64 << 10,
+		"256M":   256 << 20,
+		"1GiB":   1 << 30,
+		"1.5GB":  1500000000,
+		"10 MB":  10000000,
+		"512B":   512,
+		"2T":     2 << 40,
+		"0.5KiB": 512,
+	}
+	for spec, want := range tests {
+		got, err := ParseSize(spec)
+		if err != nil {
+			t.Errorf("ParseSize(%q) failed: %v", spec, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ParseSize(%q) = %d, want %d", spec, got, want)
+		}
+	}
+
+	for _, spec := range []string{"", "GiB", "-1MiB", "ten", "Inf", "+InfKiB", "NaN", "nanMB", "8388608TiB", "9223372036854775807", "1e30"} {
+		if _, err := ParseSize(spec); err == nil {
+			t.Errorf("ParseSize(%q) succeeded, want error", spec)
+		}
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	for _, n := range []int64{0, 1000, 1 << 10, 3 << 20, 1 << 30, 1<<30 + 1} {
+		got, err := ParseSize(FormatSize(n))
+		if err != nil || got != n {
+			t.Errorf("FormatSize(%d) = %q does not round-trip", n, FormatSize(n))
+		}
+	}
+	if got := FormatSize(1 << 30); got != "1GiB" {
+		t.Errorf("FormatSize(1GiB) = %q", got)
+	}
+}
+
+func TestParseSizes(t *testing.T) {
+	sizes, err := ParseSizes("0, 16KiB,110KiB")
+	if err != nil {
+		t.Fatalf("ParseSizes failed: %v", err)
+	}
+	if len(sizes) != 3 || sizes[0] != 0 || sizes[1] != 16<<10 || sizes[2] != 110<<10 {
+		t.Errorf("ParseSizes = %v", sizes)
+	}
+	if got := FormatSizes(sizes); got != "0,16KiB,110KiB" {
+		t.Errorf("FormatSizes = %q", got)
+	}
+	if _, err := ParseSizes("1MiB,big"); err == nil {
+		t.Error("Expected an error for an invalid size")
+	}
+}
@@ -1,8 +1,10 @@
 package util
 
 import (
+	"context"
 	"crypto/sha256"
 	"fmt"
+	"hash"
 	"io"
 	"os"
 	"os/exec"
@@ -39,34 +41,173 @@ func FileSize(path string) (int64, error) {
 	return info.Size(), nil
 }
 
-// RunCommand executes a command and returns the elapsed time
-func RunCommand(binary string, args []string, outputFile string) (time.Duration, error) {
-	start := time.Now()
-	cmd := exec.Command(binary, args...)
+// Measurement holds resource usage collected for a single command run
+type Measurement struct {
+	Wall                   time.Duration
+	User                   time.Duration
+	System                 time.Duration
+	MaxRSSBytes            int64
+	VoluntaryCtxSwitches   int64
+	InvoluntaryCtxSwitches int64
+	MinorPageFaults        int64
+	MajorPageFaults        int64
+}
+
+// CPU returns the combined user and system CPU time
+func (m Measurement) CPU() time.Duration {
+	return m.User + m.System
+}
+
+// WarmFileCache reads a file fully so that its pages are in the page cache
+func WarmFileCache(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func(f *os.File) {
+		err := f.Close()
+		if err != nil {
+			fmt.Printf("warning: failed to close file: %v\n", err)
+		}
+	}(f)
+
+	_, err = io.Copy(io.Discard, f)
+	return err
+}
 
-	// Handle stdout redirection for codecs that need it
-	if outputFile != "" && NeedsStdoutRedirection(binary) {
-		out, err := os.Create(outputFile)
+// RunCommand executes a command and returns its wall time and resource usage.
+// Non-empty stdinFile and stdoutFile connect the command's standard input and
+// output to those files. Cancelling ctx kills the command's process group.
+func RunCommand(ctx context.Context, binary string, args []string, stdinFile, stdoutFile string) (Measurement, error) {
+	var stdin io.Reader
+	if stdinFile != "" {
+		in, err := os.Open(stdinFile)
 		if err != nil {
-			return 0, err
+			return Measurement{}, err
+		}
+		defer func() {
+			if closeErr := in.Close(); closeErr != nil {
+				fmt.Printf("warning: failed to close input file: %v\n", closeErr)
+			}
+		}()
+		stdin = in
+	}
+
+	var stdout io.Writer
+	if stdoutFile != "" {
+		out, err := os.Create(stdoutFile)
+		if err != nil {
+			return Measurement{}, err
 		}
 		defer func() {
 			if closeErr := out.Close(); closeErr != nil {
 				fmt.Printf("warning: failed to close output file: %v\n", closeErr)
 			}
 		}()
-		cmd.Stdout = out
+		stdout = out
 	}
 
+	return RunPipe(ctx, binary, args, stdin, stdout)
+}
+
+// RunPipe executes a command that reads stdin and writes stdout, and returns
+// its wall time and resource usage. Cancelling ctx kills the command's
+// process group.
+func RunPipe(ctx context.Context, binary string, args []string, stdin io.Reader, stdout io.Writer) (Measurement, error) {
+	start := time.Now()
+	cmd := exec.CommandContext(ctx, binary, args...)
+	killGroupOnCancel(cmd)
+	cmd.WaitDelay = waitDelay
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
 	cmd.Stderr = nil // Discard stderr
 
-	err := cmd.Run()
-	elapsed := time.Since(start)
+	if err := cmd.Start(); err != nil {
+		return Measurement{Wall: time.Since(start)}, err
+	}
+	rss := watchRSS(cmd.Process.Pid)
+	err := cmd.Wait()
+	m := Measurement{Wall: time.Since(start)}
+	if cmd.ProcessState != nil {
+		m.User = cmd.ProcessState.UserTime()
+		m.System = cmd.ProcessState.SystemTime()
+		collectRusage(cmd.ProcessState, &m)
+	}
+	m.MaxRSSBytes = rss.childPeakRSS(m.MaxRSSBytes)
+
+	return m, err
+}
+
+// HashingWriter computes the SHA256 hash and byte count of everything written
+// to it without retaining the data
+type HashingWriter struct {
+	hash  hash.Hash
+	count int64
+}
+
+// NewHashingWriter creates an empty HashingWriter
+func NewHashingWriter() *HashingWriter {
+	return &HashingWriter{hash: sha256.New()}
+}
+
+func (w *HashingWriter) Write(p []byte) (int, error) {
+	n, err := w.hash.Write(p)
+	w.count += int64(n)
+	return n, err
+}
+
+// Sum returns the hex-encoded SHA256 of the data written so far, in the same
+// format as ComputeFileHash
+func (w *HashingWriter) Sum() string {
+	return fmt.Sprintf("%x", w.hash.Sum(nil))
+}
 
-	return elapsed, err
+// Count returns the number of bytes written so far
+func (w *HashingWriter) Count() int64 {
+	return w.count
 }
 
-// NeedsStdoutRedirection returns true if the binary writes to stdout with -c flag
-func NeedsStdoutRedirection(binary string) bool {
-	return binary == "xz" || binary == "pigz" || binary == "pbzip2"
+// waitDelay bounds how long a cancelled command may hold its I/O pipes open
+const waitDelay = 5 * time.Second
+
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
+
+// ContextReader wraps r so that reads fail with ctx's error once ctx is
+// cancelled, allowing in-process codecs to stop between reads
+func ContextReader(ctx context.Context, r io.Reader) io.Reader {
+	return &contextReader{ctx: ctx, r: r}
+}
+
+// MeasureFunc runs fn inside the compstat process and returns its wall time
+// and the resource usage the process accrued while it ran. Because the work
+// shares the process with compstat itself, MaxRSSBytes is the process-wide
+// peak rather than a per-run value, and CPU counters include any other work
+// running concurrently.
+func MeasureFunc(fn func() error) (Measurement, error) {
+	before := selfUsage()
+	start := time.Now()
+	err := fn()
+	elapsed := time.Since(start)
+	after := selfUsage()
+
+	return Measurement{
+		Wall:                   elapsed,
+		User:                   after.User - before.User,
+		System:                 after.System - before.System,
+		MaxRSSBytes:            after.MaxRSSBytes,
+		VoluntaryCtxSwitches:   after.VoluntaryCtxSwitches - before.VoluntaryCtxSwitches,
+		InvoluntaryCtxSwitches: after.InvoluntaryCtxSwitches - before.InvoluntaryCtxSwitches,
+		MinorPageFaults:        after.MinorPageFaults - before.MinorPageFaults,
+		MajorPageFaults:        after.MajorPageFaults - before.MajorPageFaults,
+	}, err
 }
@@ -1,9 +1,16 @@
 package util
 
 import (
+	"bytes"
+	"context"
+	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestFileSize(t *testing.T) {
@@ -87,27 +94,210 @@ func TestComputeFileHash(t *testing.T) {
 	}
 }
 
-func TestNeedsStdoutRedirection(t *testing.T) {
-	tests := []struct {
-		binary   string
-		expected bool
-	}{
-		{"xz", true},
-		{"pigz", true},
-		{"pbzip2", true},
-		{"gzip", false},
-		{"zstd", false},
-		{"lz4", false},
-		{"brotli", false},
-		{"unknown", false},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.binary, func(t *testing.T) {
-			result := NeedsStdoutRedirection(tt.binary)
-			if result != tt.expected {
-				t.Errorf("NeedsStdoutRedirection(%s) = %v, expected %v", tt.binary, result, tt.expected)
-			}
-		})
+func TestRunCommandRedirects(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat not available")
+	}
+	dir := t.TempDir()
+	input := filepath.Join(dir, "in.txt")
+	output := filepath.Join(dir, "out.txt")
+	if err := os.WriteFile(input, []byte("redirected"), 0644); err != nil {
+		t.Fatalf("Failed to create input: %v", err)
+	}
+
+	if _, err := RunCommand(context.Background(), "cat", nil, input, output); err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	got, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	if string(got) != "redirected" {
+		t.Errorf("Expected stdin copied to the stdout file, got %q", got)
+	}
+
+	if _, err := RunCommand(context.Background(), "cat", nil, filepath.Join(dir, "missing"), output); err == nil {
+		t.Error("Expected error for a missing stdin file")
+	}
+}
+
+func TestRunPipeRoundTrip(t *testing.T) {
+	if _, err := exec.LookPath("gzip"); err != nil {
+		t.Skip("gzip not available")
+	}
+	input := filepath.Join(t.TempDir(), "in.txt")
+	data := []byte(strings.Repeat("compstat pipe round trip\n", 40000))
+	if err := os.WriteFile(input, data, 0644); err != nil {
+		t.Fatalf("Failed to create input: %v", err)
+	}
+	want, err := ComputeFileHash(input)
+	if err != nil {
+		t.Fatalf("ComputeFileHash failed: %v", err)
+	}
+
+	var compressed bytes.Buffer
+	m, err := RunPipe(context.Background(), "gzip", []string{"-c"}, bytes.NewReader(data), &compressed)
+	if err != nil {
+		t.Fatalf("RunPipe compression failed: %v", err)
+	}
+	if m.Wall <= 0 || compressed.Len() == 0 || compressed.Len() >= len(data) {
+		t.Fatalf("Unexpected compression: %d of %d bytes in %v", compressed.Len(), len(data), m.Wall)
+	}
+
+	sink := NewHashingWriter()
+	if _, err := RunPipe(context.Background(), "gzip", []string{"-d", "-c"}, &compressed, sink); err != nil {
+		t.Fatalf("RunPipe decompression failed: %v", err)
+	}
+	if sink.Count() != int64(len(data)) || sink.Sum() != want {
+		t.Errorf("Round trip gave %d bytes with hash %s, want %d bytes with hash %s", sink.Count(), sink.Sum(), len(data), want)
+	}
+
+	if _, err := RunPipe(context.Background(), "gzip", []string{"-d", "-c"}, strings.NewReader("not gzip"), io.Discard); err == nil {
+		t.Error("Expected an error for corrupt input")
+	}
+}
+
+func TestRunCommandReportsResourceUsage(t *testing.T) {
+	if _, err := exec.LookPath("true"); err != nil {
+		t.Skip("true not available")
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("rusage not supported on windows")
+	}
+
+	m, err := RunCommand(context.Background(), "true", nil, "", "")
+	if err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	if m.Wall <= 0 {
+		t.Errorf("Expected positive wall time, got %v", m.Wall)
+	}
+	if m.MaxRSSBytes <= 0 {
+		t.Errorf("Expected positive peak RSS, got %d", m.MaxRSSBytes)
+	}
+
+	// A child that spins for a while must be charged CPU time
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	busy := []string{"-c", "i=0; while [ $i -lt 300000 ]; do i=$((i+1)); done"}
+	m, err = RunCommand(context.Background(), "sh", busy, "", "")
+	if err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	if m.CPU() <= 0 || m.User <= 0 {
+		t.Errorf("Expected CPU time for a busy child, got user %v, system %v", m.User, m.System)
+	}
+	if m.CPU() > m.Wall*time.Duration(runtime.NumCPU())+10*time.Millisecond {
+		t.Errorf("CPU time %v exceeds what %v of wall time allows", m.CPU(), m.Wall)
+	}
+}
+
+func TestRunCommandExcludesParentPeakRSS(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("child peak RSS sampling is linux-only")
+	}
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep not available")
+	}
+
+	// Raise compstat's own peak well above anything sleep needs
+	const inflate = 256 << 20
+	ballast := make([]byte, inflate)
+	for i := 0; i < len(ballast); i += 4096 {
+		ballast[i] = 1
+	}
+
+	m, err := RunCommand(context.Background(), "sleep", []string{"0.1"}, "", "")
+	runtime.KeepAlive(ballast)
+	if err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	if m.MaxRSSBytes <= 0 {
+		t.Errorf("Expected positive peak RSS, got %d", m.MaxRSSBytes)
+	}
+	if parent := selfUsage().MaxRSSBytes; parent < inflate {
+		t.Fatalf("Parent peak RSS %d was not inflated", parent)
+	}
+	if m.MaxRSSBytes >= inflate/4 {
+		t.Errorf("Child peak RSS %d includes the parent's peak", m.MaxRSSBytes)
+	}
+}
+
+func TestHashingWriterMatchesFileHash(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "test.txt")
+	content := []byte("Hello, World!")
+	if err := os.WriteFile(tmpFile, content, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	fileHash, err := ComputeFileHash(tmpFile)
+	if err != nil {
+		t.Fatalf("ComputeFileHash failed: %v", err)
+	}
+
+	w := NewHashingWriter()
+	if _, err := w.Write(content[:5]); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if _, err := w.Write(content[5:]); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	if w.Sum() != fileHash {
+		t.Errorf("Expected hash %s, got %s", fileHash, w.Sum())
+	}
+	if w.Count() != int64(len(content)) {
+		t.Errorf("Expected count %d, got %d", len(content), w.Count())
+	}
+}
+
+func TestFileCacheControl(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "test.txt")
+	if err := os.WriteFile(tmpFile, []byte("Hello, World!"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	if err := WarmFileCache(tmpFile); err != nil {
+		t.Errorf("WarmFileCache failed: %v", err)
+	}
+
+	err := DropFileCache(tmpFile)
+	if runtime.GOOS == "linux" && err != nil {
+		t.Errorf("DropFileCache failed: %v", err)
+	}
+	if runtime.GOOS != "linux" && err == nil {
+		t.Error("Expected DropFileCache to be unsupported")
+	}
+}
+
+func TestRunCommandCancel(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep not available")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	m, err := RunCommand(ctx, "sleep", []string{"10"}, "", "")
+	if err == nil {
+		t.Fatal("Expected error from cancelled command")
+	}
+	if m.Wall > 5*time.Second {
+		t.Errorf("Expected cancelled command to stop promptly, took %v", m.Wall)
+	}
+}
+
+func TestContextReader(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := ContextReader(ctx, strings.NewReader("data"))
+
+	buf := make([]byte, 2)
+	if _, err := r.Read(buf); err != nil {
+		t.Fatalf("Read before cancel failed: %v", err)
+	}
+	cancel()
+	if _, err := r.Read(buf); err != context.Canceled {
+		t.Errorf("Expected context.Canceled after cancel, got %v", err)
 	}
 }